@@ -0,0 +1,182 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+const PLACEHOLDER_SIZE = 16
+
+var PLACEHOLDER_COLOUR = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+
+// AssetError describes a registered resource that could not be read or decoded.
+type AssetError struct {
+	Kind string
+	ID   int
+	Path string
+	Err  error
+}
+
+func (err *AssetError) Error() string {
+	return fmt.Sprintf("%s %d (%s): %v", err.Kind, err.ID, err.Path, err.Err)
+}
+
+func (err *AssetError) Unwrap() error {
+	return err.Err
+}
+
+// checkAsset decodes data the way the loader will, based on the file extension.
+func checkAsset(path string, data []byte) error {
+	var err error
+	switch filepath.Ext(path) {
+	case ".png":
+		_, _, err = image.Decode(bytes.NewReader(data))
+	case ".ttf":
+		_, err = opentype.Parse(data)
+	case ".wav":
+		_, err = wav.DecodeWithoutResampling(bytes.NewReader(data))
+	case ".json":
+		if !json.Valid(data) {
+			err = errors.New("invalid JSON")
+		}
+	}
+	return err
+}
+
+// CheckedAssets holds the assets CheckAssets read, so that the loader can open them
+// without reading and decoding them again.
+type CheckedAssets struct {
+	Problems []*AssetError
+	read     func(path string) ([]byte, error)
+	data     map[string][]byte
+}
+
+// Open is a resource.Loader OpenAssetFunc giving each asset as it was checked, or its
+// placeholder if it was broken. The loader keeps what it opens, so each asset is only
+// held until then. Assets opened again, or that were not checked, are checked as they
+// are read.
+func (checked *CheckedAssets) Open(path string) io.ReadCloser {
+	data, ok := checked.data[path]
+	if !ok {
+		return openOrPlaceholder(path, checked.read)
+	}
+	delete(checked.data, path)
+	return io.NopCloser(bytes.NewReader(data))
+}
+
+// CheckAssets reads and decodes every registered image, sound, font and manifest with read,
+// with an error for each one that is missing or corrupt, ordered by kind and ID.
+func CheckAssets(read func(path string) ([]byte, error)) *CheckedAssets {
+	checked := &CheckedAssets{read: read, data: map[string][]byte{}}
+	var problems []*AssetError
+	check := func(kind string, id int, path string) {
+		data, err := read(path)
+		if err == nil {
+			err = checkAsset(path, data)
+		}
+		if err != nil {
+			problems = append(problems, &AssetError{Kind: kind, ID: id, Path: path, Err: err})
+			data = placeholder(path)
+		}
+		checked.data[path] = data
+	}
+	for id, res := range IMAGE_RESOURCES {
+		check("image", int(id), res.Path)
+	}
+	for id, res := range FONT_RESOURCES {
+		check("font", int(id), res.Path)
+	}
+	for id, res := range AUDIO_RESOURCES {
+		check("audio", int(id), res.Path)
+	}
+	for id, res := range RAW_RESOURCES {
+		check("raw", int(id), res.Path)
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		return problems[i].ID < problems[j].ID
+	})
+	checked.Problems = problems
+	return checked
+}
+
+func openOrPlaceholder(path string, read func(path string) ([]byte, error)) io.ReadCloser {
+	data, err := read(path)
+	if err == nil {
+		err = checkAsset(path, data)
+	}
+	if err != nil {
+		data = placeholder(path)
+	}
+	return io.NopCloser(bytes.NewReader(data))
+}
+
+// placeholder stands in for a broken asset: a magenta sprite, a stock font, silence
+// or an empty manifest, which leaves every setting at its default.
+func placeholder(path string) []byte {
+	switch filepath.Ext(path) {
+	case ".json":
+		return []byte("{}")
+	case ".ttf":
+		return goregular.TTF
+	case ".wav":
+		return silentWAV()
+	default:
+		return placeholderPNG()
+	}
+}
+
+func placeholderPNG() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, PLACEHOLDER_SIZE, PLACEHOLDER_SIZE))
+	for y := 0; y < PLACEHOLDER_SIZE; y++ {
+		for x := 0; x < PLACEHOLDER_SIZE; x++ {
+			if (x/4+y/4)%2 == 0 {
+				img.Set(x, y, PLACEHOLDER_COLOUR)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	png.Encode(buf, img)
+	return buf.Bytes()
+}
+
+// silentWAV is a single silent 16 bit stereo sample.
+func silentWAV() []byte {
+	const sampleRate, channels, bitsPerSample = 44100, 2, 16
+	samples := make([]byte, channels*bitsPerSample/8)
+	buf := &bytes.Buffer{}
+	write := func(v interface{}) {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("RIFF")
+	write(uint32(36 + len(samples)))
+	buf.WriteString("WAVEfmt ")
+	write(uint32(16))
+	write(uint16(1))
+	write(uint16(channels))
+	write(uint32(sampleRate))
+	write(uint32(sampleRate * channels * bitsPerSample / 8))
+	write(uint16(channels * bitsPerSample / 8))
+	write(uint16(bitsPerSample))
+	buf.WriteString("data")
+	write(uint32(len(samples)))
+	buf.Write(samples)
+	return buf.Bytes()
+}
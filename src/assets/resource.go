@@ -13,94 +13,116 @@ const (
 	ImgNone resource.ImageID = iota
 	ImgGround2D
 	ImgGroundIso
-	ImgBlueCube2D
-	ImgBlueCubeIso
-	ImgBlueHalfCube2D
-	ImgBlueHalfCubeIso
-	ImgRedCube2D
-	ImgRedCubeIso
-	ImgRedHalfCube2D
-	ImgRedHalfCubeIso
-	ImgYellowCube2D
-	ImgYellowCubeIso
-	ImgYellowHalfCube2D
-	ImgYellowHalfCubeIso
-	ImgViewBtn2D
-	ImgViewBtnIso
-	ImgViewBtnDisabled
+	ImgBlockCube2D
+	ImgBlockCubeIso
+	ImgBlockHalfCube2D
+	ImgBlockHalfCubeIso
 	ImgCursorBtnIdle
 	ImgCursorBtnSelected
-	ImgBlueBlockBtnIdle
-	ImgBlueBlockBtnSelected
-	ImgRedBlockBtnIdle
-	ImgRedBlockBtnSelected
-	ImgYellowBlockBtnIdle
-	ImgYellowBlockBtnSelected
+	ImgBlockBtnIdle
+	ImgBlockBtnSelected
+	ImgBlockBtnSwatch
 	ImgPanelBtnDisabled
-	ImgSizeBtnFull
-	ImgSizeBtnHalf
-	ImgSizeBtnDisabled
+	ImgTextBtnIdle
+	ImgTextBtnHover
+	ImgTextBtnSelected
+	ImgTextBtnDisabled
+	ImgIconInfo
+	ImgIconWarning
+	ImgIconError
 )
 
-func RegisterImageResources(loader *resource.Loader) {
-	imageResources := map[resource.ImageID]resource.ImageInfo{
-		ImgGround2D:               {Path: "ground-2d.png"},
-		ImgGroundIso:              {Path: "ground-iso.png"},
-		ImgBlueCube2D:             {Path: "blue-2d-cube.png"},
-		ImgBlueCubeIso:            {Path: "blue-iso-cube.png"},
-		ImgBlueHalfCube2D:         {Path: "blue-2d-half-cube.png"},
-		ImgBlueHalfCubeIso:        {Path: "blue-iso-half-cube.png"},
-		ImgRedCube2D:              {Path: "red-2d-cube.png"},
-		ImgRedCubeIso:             {Path: "red-iso-cube.png"},
-		ImgRedHalfCube2D:          {Path: "red-2d-half-cube.png"},
-		ImgRedHalfCubeIso:         {Path: "red-iso-half-cube.png"},
-		ImgYellowCube2D:           {Path: "yellow-2d-cube.png"},
-		ImgYellowCubeIso:          {Path: "yellow-iso-cube.png"},
-		ImgYellowHalfCube2D:       {Path: "yellow-2d-half-cube.png"},
-		ImgYellowHalfCubeIso:      {Path: "yellow-iso-half-cube.png"},
-		ImgViewBtn2D:              {Path: "view-btn-2d.png"},
-		ImgViewBtnIso:             {Path: "view-btn-iso.png"},
-		ImgViewBtnDisabled:        {Path: "view-btn-disabled.png"},
-		ImgCursorBtnIdle:          {Path: "cursor-btn-idle.png"},
-		ImgCursorBtnSelected:      {Path: "cursor-btn-selected.png"},
-		ImgBlueBlockBtnIdle:       {Path: "blue-block-btn-idle.png"},
-		ImgBlueBlockBtnSelected:   {Path: "blue-block-btn-selected.png"},
-		ImgRedBlockBtnIdle:        {Path: "red-block-btn-idle.png"},
-		ImgRedBlockBtnSelected:    {Path: "red-block-btn-selected.png"},
-		ImgYellowBlockBtnIdle:     {Path: "yellow-block-btn-idle.png"},
-		ImgYellowBlockBtnSelected: {Path: "yellow-block-btn-selected.png"},
-		ImgPanelBtnDisabled:       {Path: "panel-btn-disabled.png"},
-		ImgSizeBtnFull:            {Path: "size-btn-full.png"},
-		ImgSizeBtnHalf:            {Path: "size-btn-half.png"},
-		ImgSizeBtnDisabled:        {Path: "size-btn-disabled.png"},
-	}
+var IMAGE_RESOURCES = map[resource.ImageID]resource.ImageInfo{
+	ImgGround2D:          {Path: "ground-2d.png"},
+	ImgGroundIso:         {Path: "ground-iso.png"},
+	ImgBlockCube2D:       {Path: "block-2d-cube.png"},
+	ImgBlockCubeIso:      {Path: "block-iso-cube.png"},
+	ImgBlockHalfCube2D:   {Path: "block-2d-half-cube.png"},
+	ImgBlockHalfCubeIso:  {Path: "block-iso-half-cube.png"},
+	ImgCursorBtnIdle:     {Path: "cursor-btn-idle.png"},
+	ImgCursorBtnSelected: {Path: "cursor-btn-selected.png"},
+	ImgBlockBtnIdle:      {Path: "block-btn-idle.png"},
+	ImgBlockBtnSelected:  {Path: "block-btn-selected.png"},
+	ImgBlockBtnSwatch:    {Path: "block-btn-swatch.png"},
+	ImgPanelBtnDisabled:  {Path: "panel-btn-disabled.png"},
+	ImgTextBtnIdle:       {Path: "text-btn-idle.png"},
+	ImgTextBtnHover:      {Path: "text-btn-hover.png"},
+	ImgTextBtnSelected:   {Path: "text-btn-selected.png"},
+	ImgTextBtnDisabled:   {Path: "text-btn-disabled.png"},
+	ImgIconInfo:          {Path: "icon-info.png"},
+	ImgIconWarning:       {Path: "icon-warning.png"},
+	ImgIconError:         {Path: "icon-error.png"},
+}
 
-	for id, res := range imageResources {
+func RegisterImageResources(loader *resource.Loader) {
+	for id, res := range IMAGE_RESOURCES {
 		loader.ImageRegistry.Set(id, res)
 		loader.LoadImage(id)
 	}
 }
 
+const (
+	AudioNone resource.AudioID = iota
+	AudioPlaceHalf
+	AudioPlaceFull
+	AudioDelete
+	AudioToggleRenderer
+	AudioAlert
+)
+
+var AUDIO_RESOURCES = map[resource.AudioID]resource.AudioInfo{
+	AudioPlaceHalf:      {Path: "place-half.wav"},
+	AudioPlaceFull:      {Path: "place-full.wav"},
+	AudioDelete:         {Path: "delete.wav"},
+	AudioToggleRenderer: {Path: "toggle-renderer.wav"},
+	AudioAlert:          {Path: "alert.wav", Volume: -0.2},
+}
+
+func RegisterAudioResources(loader *resource.Loader) {
+	for id, res := range AUDIO_RESOURCES {
+		loader.AudioRegistry.Set(id, res)
+		loader.LoadAudio(id)
+	}
+}
+
 const (
 	FontDefault resource.FontID = iota
 )
 
+var FONT_RESOURCES = map[resource.FontID]resource.FontInfo{
+	FontDefault: {Path: "fibberish.ttf", Size: 12},
+}
+
 func RegisterFontResources(loader *resource.Loader) {
-	fontResources := map[resource.FontID]resource.FontInfo{
-		FontDefault: {Path: "fibberish.ttf", Size: 12},
-	}
-	for id, res := range fontResources {
+	for id, res := range FONT_RESOURCES {
 		loader.FontRegistry.Set(id, res)
 		loader.LoadFont(id)
 	}
 }
 
-func OpenAssetFunc(path string) io.ReadCloser {
-	f, err := gameAssets.Open("resources/" + path)
-	if err != nil {
-		panic(err)
+const (
+	RawNone resource.RawID = iota
+	RawTileset
+)
+
+var RAW_RESOURCES = map[resource.RawID]resource.RawInfo{
+	RawTileset: {Path: "tileset.json"},
+}
+
+func RegisterRawResources(loader *resource.Loader) {
+	for id, res := range RAW_RESOURCES {
+		loader.RawRegistry.Set(id, res)
+		loader.LoadRaw(id)
 	}
-	return f
+}
+
+// OpenAssetFunc opens a built in asset, substituting a placeholder if it is missing or corrupt.
+func OpenAssetFunc(path string) io.ReadCloser {
+	return openOrPlaceholder(path, ReadAsset)
+}
+
+func ReadAsset(path string) ([]byte, error) {
+	return gameAssets.ReadFile("resources/" + path)
 }
 
 //go:embed all:resources
@@ -0,0 +1,217 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
+)
+
+// TexturePack shadows the embedded resources with files of the same name in a
+// directory. Files that fail validation are reported and the embedded asset is used.
+type TexturePack struct {
+	dir      string
+	sizes    map[string]image.Point
+	files    map[string]time.Time
+	problems []error
+}
+
+// NewTexturePack reads overrides from dir.
+func NewTexturePack(dir string) *TexturePack {
+	pack := &TexturePack{
+		dir:   dir,
+		sizes: map[string]image.Point{},
+	}
+	pack.files = pack.scan()
+	for name := range pack.files {
+		if !isResource(name) {
+			pack.report(fmt.Errorf("%s: not a game asset, ignored", name))
+		}
+	}
+	return pack
+}
+
+// SetSpriteSizes sets the dimensions that sprites must have, such as those the board
+// grid depends on. Sprites read before it is called are not checked.
+func (pack *TexturePack) SetSpriteSizes(sizes map[resource.ImageID]image.Point) {
+	pack.sizes = map[string]image.Point{}
+	for id, size := range sizes {
+		pack.sizes[IMAGE_RESOURCES[id].Path] = size
+	}
+}
+
+func isResource(name string) bool {
+	for _, res := range IMAGE_RESOURCES {
+		if res.Path == name {
+			return true
+		}
+	}
+	for _, res := range AUDIO_RESOURCES {
+		if res.Path == name {
+			return true
+		}
+	}
+	for _, res := range FONT_RESOURCES {
+		if res.Path == name {
+			return true
+		}
+	}
+	for _, res := range RAW_RESOURCES {
+		if res.Path == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (pack *TexturePack) Dir() string {
+	return pack.dir
+}
+
+// report records a problem unless it is already waiting to be collected, since the
+// same file is read by both the asset check and the loader.
+func (pack *TexturePack) report(err error) {
+	err = fmt.Errorf("texture pack: %w", err)
+	for _, problem := range pack.problems {
+		if problem.Error() == err.Error() {
+			return
+		}
+	}
+	pack.problems = append(pack.problems, err)
+}
+
+// Problems returns and clears the problems found since the last call.
+func (pack *TexturePack) Problems() []error {
+	problems := pack.problems
+	pack.problems = nil
+	return problems
+}
+
+// Open is a resource.Loader OpenAssetFunc preferring a valid override in the pack.
+func (pack *TexturePack) Open(path string) io.ReadCloser {
+	return openOrPlaceholder(path, pack.Read)
+}
+
+// Read returns the override for path if it is valid, and otherwise the built in asset.
+func (pack *TexturePack) Read(path string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(pack.dir, path))
+	if os.IsNotExist(err) {
+		return ReadAsset(path)
+	}
+	if err == nil {
+		err = pack.validate(path, data)
+	}
+	if err != nil {
+		pack.report(fmt.Errorf("%s: %w, using the built in asset", path, err))
+		return ReadAsset(path)
+	}
+	return data, nil
+}
+
+func (pack *TexturePack) validate(path string, data []byte) error {
+	if err := checkAsset(path, data); err != nil {
+		return err
+	}
+	if filepath.Ext(path) != ".png" {
+		return nil
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if size, ok := pack.sizes[path]; ok && (config.Width != size.X || config.Height != size.Y) {
+		return fmt.Errorf("sprite is %dx%d but must be %dx%d", config.Width, config.Height, size.X, size.Y)
+	}
+	return nil
+}
+
+func (pack *TexturePack) scan() map[string]time.Time {
+	files := map[string]time.Time{}
+	entries, err := os.ReadDir(pack.dir)
+	if err != nil {
+		// Reported when the pack is opened or the directory goes away, not on every poll.
+		if len(pack.files) > 0 || pack.files == nil {
+			pack.report(err)
+		}
+		return files
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && !entry.IsDir() {
+			files[entry.Name()] = info.ModTime()
+		}
+	}
+	return files
+}
+
+// Reload reloads the images whose override files were added, changed or removed
+// since the last call, returning how many were updated. Images are redrawn in place,
+// so a replacement must be the same size as the image it replaces. Audio, fonts and
+// the tileset manifest are only read at startup.
+func (pack *TexturePack) Reload(loader *resource.Loader) int {
+	files := pack.scan()
+	changed := map[string]bool{}
+	for name, modTime := range files {
+		if previous, ok := pack.files[name]; !ok || !previous.Equal(modTime) {
+			changed[name] = true
+		}
+	}
+	for name := range pack.files {
+		if _, ok := files[name]; !ok {
+			changed[name] = true
+		}
+	}
+	pack.files = files
+
+	reloaded := 0
+	for name := range changed {
+		id, ok := imageID(name)
+		if !ok {
+			if isResource(name) {
+				pack.report(fmt.Errorf("%s: changes to sounds, fonts and the tileset apply after a restart", name))
+			} else if _, exists := files[name]; exists {
+				pack.report(fmt.Errorf("%s: not a game asset, ignored", name))
+			}
+			continue
+		}
+		if pack.reloadImage(loader, id, name) {
+			reloaded++
+		}
+	}
+	return reloaded
+}
+
+func imageID(path string) (resource.ImageID, bool) {
+	for id, res := range IMAGE_RESOURCES {
+		if res.Path == path {
+			return id, true
+		}
+	}
+	return ImgNone, false
+}
+
+func (pack *TexturePack) reloadImage(loader *resource.Loader, id resource.ImageID, path string) bool {
+	r := pack.Open(path)
+	defer r.Close()
+	decoded, _, err := image.Decode(r)
+	if err != nil {
+		pack.report(fmt.Errorf("%s: %w", path, err))
+		return false
+	}
+	target := loader.LoadImage(id).Data
+	if decoded.Bounds().Size() != target.Bounds().Size() {
+		pack.report(fmt.Errorf("%s: sprite is %dx%d but the loaded one is %dx%d, restart to use it",
+			path, decoded.Bounds().Dx(), decoded.Bounds().Dy(), target.Bounds().Dx(), target.Bounds().Dy()))
+		return false
+	}
+	replacement := ebiten.NewImageFromImage(decoded)
+	target.Clear()
+	target.DrawImage(replacement, nil)
+	replacement.Dispose()
+	return true
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+)
+
+// readAssets returns how the game reads assets, through the texture pack if there is one.
+func readAssets(texturePack *assets.TexturePack) func(path string) ([]byte, error) {
+	if texturePack == nil {
+		return assets.ReadAsset
+	}
+	return texturePack.Read
+}
+
+// CheckAssets decodes every asset, including overrides in texturePackDir if it is set,
+// and writes a line to out for each problem. It fails if there were any.
+func CheckAssets(texturePackDir string, out io.Writer) error {
+	texturePack := openTexturePack(texturePackDir)
+	problems := []error{}
+	for _, err := range assets.CheckAssets(readAssets(texturePack)).Problems {
+		problems = append(problems, err)
+	}
+	if _, err := objects.LoadTileset(readAssets(texturePack)); err != nil {
+		problems = append(problems, err)
+	}
+	if texturePack != nil {
+		problems = append(problems, texturePack.Problems()...)
+	}
+	for _, err := range problems {
+		fmt.Fprintln(out, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d asset problems", len(problems))
+	}
+	fmt.Fprintln(out, "all assets OK")
+	return nil
+}
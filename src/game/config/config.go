@@ -1,7 +1,14 @@
 package config
 
 const (
-	Scale        = 2
 	ScreenWidth  = 720
 	ScreenHeight = 480
 )
+
+// The logical screen never shrinks below this, whatever the window size.
+const (
+	MinScreenWidth  = 480
+	MinScreenHeight = 320
+)
+
+const DefaultReplayPath = "replay.bpr"
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+)
+
+const SETTINGS_FILE = "settings.json"
+
+const (
+	MIN_SCALE = 1
+	MAX_SCALE = 4
+)
+
+type BackgroundColour struct {
+	Name   string
+	Colour color.RGBA
+}
+
+var BACKGROUND_COLOURS = []BackgroundColour{
+	{Name: "NAVY", Colour: color.RGBA{R: 21, G: 29, B: 40, A: 255}},  // #151d28
+	{Name: "BLACK", Colour: color.RGBA{R: 9, G: 10, B: 20, A: 255}},  // #090a14
+	{Name: "SLATE", Colour: color.RGBA{R: 57, G: 74, B: 80, A: 255}}, // #394a50
+	{Name: "PLUM", Colour: color.RGBA{R: 36, G: 21, B: 39, A: 255}},  // #241527
+	{Name: "MOSS", Colour: color.RGBA{R: 25, G: 51, B: 45, A: 255}},  // #19332d
+}
+
+// Settings are the user's display preferences, saved as JSON in the user config directory.
+type Settings struct {
+	Fullscreen   bool
+	VSync        bool
+	Scale        int
+	PixelPerfect bool
+	Background   string
+	HoverTint    int
+	Animations   bool
+	// TexturePack is a directory of files overriding the built in assets, read at startup.
+	TexturePack string
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		VSync:      true,
+		Scale:      2,
+		Background: BACKGROUND_COLOURS[0].Name,
+		HoverTint:  100,
+		Animations: true,
+	}
+}
+
+// BackgroundColour returns the chosen background, or the first one if the name is unknown.
+func (settings *Settings) BackgroundColour() color.RGBA {
+	return BACKGROUND_COLOURS[settings.BackgroundIndex()].Colour
+}
+
+func (settings *Settings) BackgroundIndex() int {
+	for i, background := range BACKGROUND_COLOURS {
+		if background.Name == settings.Background {
+			return i
+		}
+	}
+	return 0
+}
+
+// clamp repairs values edited by hand into something usable.
+func (settings *Settings) clamp() {
+	if settings.Scale < MIN_SCALE {
+		settings.Scale = MIN_SCALE
+	}
+	if settings.Scale > MAX_SCALE {
+		settings.Scale = MAX_SCALE
+	}
+	if settings.HoverTint < 0 {
+		settings.HoverTint = 0
+	}
+	if settings.HoverTint > 100 {
+		settings.HoverTint = 100
+	}
+	settings.Background = BACKGROUND_COLOURS[settings.BackgroundIndex()].Name
+}
+
+func SettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-game-block-placement", SETTINGS_FILE), nil
+}
+
+// PrefabLibraryPath is the directory prefabs are saved to when no other is given.
+func PrefabLibraryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-game-block-placement", "prefabs"), nil
+}
+
+// LoadSettings reads path, falling back to the defaults for a missing file or fields.
+func LoadSettings(path string) (Settings, error) {
+	settings := DefaultSettings()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return DefaultSettings(), fmt.Errorf("reading %s: %w", path, err)
+	}
+	settings.clamp()
+	return settings, nil
+}
+
+func SaveSettings(path string, settings Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
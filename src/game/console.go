@@ -0,0 +1,475 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/script"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+var CONSOLE_USAGE = map[string]string{
+	"help":      "help",
+	"clear":     "clear",
+	"fill":      "fill COLOUR [SIZE]",
+	"sizes":     "sizes COLOUR [SIZE...]",
+	"replace":   "replace COLOUR COLOUR",
+	"resize":    "resize WIDTH LENGTH [DEPTH]",
+	"save":      "save [FILE]",
+	"load":      "load [FILE]",
+	"renderer":  "renderer iso|2d|side",
+	"overlay":   "overlay grid|heights|rulers|heatmap",
+	"tool":      "tool build|fill|measure|note",
+	"notes":     "notes",
+	"prefabs":   "prefabs",
+	"maxheight": "maxheight BLOCKS",
+	"stats":     "stats",
+	"report":    "report FILE.csv|FILE.json",
+	"undo":      "undo",
+	"run":       "run FILE",
+	"export":    "export FILE.vox|FILE.obj",
+	"import":    "import FILE.vox",
+	"heightmap": "heightmap FILE.png [COLOURS.png]",
+	"rules":     "rules",
+	"rule":      "rule NAME",
+}
+
+// CONSOLE_ARGUMENTS lists the completions for the first argument of a command.
+var CONSOLE_ARGUMENTS = map[string][]string{
+	"renderer": {"iso", "2d", "side"},
+	"overlay":  {"grid", "heights", "rulers", "heatmap"},
+	"tool":     {"build", "fill", "measure", "note"},
+	"rule":     objects.OptionalRuleNames(),
+}
+
+var CONSOLE_TOOLS = map[string]ui.Tool{
+	"build":   ui.TOOL_BUILD,
+	"fill":    ui.TOOL_FILL,
+	"measure": ui.TOOL_MEASURE,
+	"note":    ui.TOOL_NOTE,
+}
+
+var CONSOLE_RENDERERS = map[string]ui.Renderer{
+	"iso":  ui.ISOMETRIC,
+	"2d":   ui.TWO_DIMENSIONAL,
+	"side": ui.ELEVATION,
+}
+
+func (g *Game) newConsoleHandlers() *ui.ConsoleHandlers {
+	return &ui.ConsoleHandlers{
+		Execute:  g.executeCommand,
+		Complete: g.completeCommand,
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (g *Game) completeCommand(line string) []string {
+	fields := strings.Fields(line)
+	candidates := []string{}
+	switch {
+	case len(fields) <= 1 && !strings.HasSuffix(line, " "):
+		prefix := strings.TrimSpace(line)
+		for _, name := range sortedKeys(CONSOLE_USAGE) {
+			if strings.HasPrefix(name, prefix) {
+				candidates = append(candidates, name)
+			}
+		}
+	case len(fields) == 1 || (len(fields) == 2 && !strings.HasSuffix(line, " ")):
+		prefix := ""
+		if len(fields) == 2 {
+			prefix = fields[1]
+		}
+		arguments := CONSOLE_ARGUMENTS[fields[0]]
+		if fields[0] == "fill" || fields[0] == "sizes" || fields[0] == "replace" {
+			arguments = g.colourNames()
+		}
+		for _, argument := range arguments {
+			if strings.HasPrefix(argument, prefix) {
+				candidates = append(candidates, fields[0]+" "+argument)
+			}
+		}
+	}
+	return candidates
+}
+
+func parseInts(args []string) ([]int, error) {
+	values := make([]int, len(args))
+	for i, arg := range args {
+		value, err := strconv.Atoi(arg)
+		if err != nil || value < 1 {
+			return nil, fmt.Errorf("%q is not a positive number", arg)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// sharedBoardCommand reports why a command that replaces the whole board cannot run.
+func (g *Game) sharedBoardCommand() string {
+	if g.client != nil {
+		return "not available during a shared session"
+	}
+	if g.replayPlayer != nil {
+		return "not available during replay playback"
+	}
+	return ""
+}
+
+func (g *Game) executeCommand(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	name, args := fields[0], fields[1:]
+	usage, ok := CONSOLE_USAGE[name]
+	if !ok {
+		return []string{fmt.Sprintf("unknown command %q, try help", name)}
+	}
+
+	switch name {
+	case "help":
+		lines := []string{}
+		for _, command := range sortedKeys(CONSOLE_USAGE) {
+			lines = append(lines, CONSOLE_USAGE[command])
+		}
+		return []string{strings.Join(lines, ", ")}
+	case "clear":
+		g.board.Clear()
+		return []string{"board cleared"}
+	case "fill":
+		if len(args) < 1 || len(args) > 2 {
+			break
+		}
+		colour, ok := g.board.Palette().Lookup(args[0])
+		if !ok {
+			break
+		}
+		size := g.board.Palette().NearestSize(colour, ui.FULL)
+		if len(args) == 2 {
+			var err error
+			if size, err = ui.ParseBlockSize(args[1]); err != nil {
+				return []string{err.Error()}
+			}
+		}
+		if !g.board.Palette().HasSize(colour, size) {
+			return []string{fmt.Sprintf("%s blocks do not come in size %s", args[0], size)}
+		}
+		return g.fill(colour, size)
+	case "sizes":
+		if len(args) < 1 {
+			break
+		}
+		palette := g.board.Palette()
+		colour, ok := palette.Lookup(args[0])
+		if !ok {
+			break
+		}
+		if len(args) > 1 {
+			if reason := g.sharedBoardCommand(); reason != "" {
+				return []string{reason}
+			}
+			sizes := []ui.BlockSize{}
+			for _, arg := range args[1:] {
+				size, err := ui.ParseBlockSize(arg)
+				if err != nil {
+					return []string{err.Error()}
+				}
+				sizes = append(sizes, size)
+			}
+			palette.SetSizes(colour, sizes)
+		}
+		names := []string{}
+		for _, size := range palette.Sizes(colour) {
+			names = append(names, size.String())
+		}
+		return []string{palette.Name(colour) + " sizes: " + strings.Join(names, ", ")}
+	case "replace":
+		if len(args) != 2 {
+			break
+		}
+		from, fromOK := g.board.Palette().Lookup(args[0])
+		to, toOK := g.board.Palette().Lookup(args[1])
+		if !fromOK || !toOK {
+			break
+		}
+		result, err := g.board.ReplaceColour(from, to)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return []string{result.String()}
+	case "resize":
+		if len(args) < 2 || len(args) > 3 {
+			break
+		}
+		if reason := g.sharedBoardCommand(); reason != "" {
+			return []string{reason}
+		}
+		values, err := parseInts(args)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		replay := g.board.Replay()
+		depth := replay.Depth
+		if len(values) == 3 {
+			depth = values[2]
+		}
+		if err := objects.CheckBoardSize(values[0], values[1], depth); err != nil {
+			return []string{err.Error()}
+		}
+		g.board.Reset(values[0], values[1], depth)
+		g.ui.State.LayerHeight = g.board.MaxHeight()
+		return []string{fmt.Sprintf("board resized to %dx%dx%d", values[0], values[1], depth)}
+	case "save", "load":
+		if len(args) > 1 {
+			break
+		}
+		path := g.replayPath
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if name == "save" {
+			if err := objects.SaveReplay(path, g.board.Replay()); err != nil {
+				return []string{err.Error()}
+			}
+			return []string{"saved " + path}
+		}
+		if reason := g.sharedBoardCommand(); reason != "" {
+			return []string{reason}
+		}
+		replay, err := objects.LoadReplay(path)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		if err := objects.ApplyReplay(g.board, replay); err != nil {
+			return []string{err.Error()}
+		}
+		g.ui.State.LayerHeight = g.board.MaxHeight()
+		return []string{"loaded " + path}
+	case "renderer":
+		if len(args) != 1 {
+			break
+		}
+		renderer, ok := CONSOLE_RENDERERS[args[0]]
+		if !ok {
+			break
+		}
+		g.ui.State.Renderer = renderer
+		return nil
+	case "overlay":
+		if len(args) != 1 {
+			break
+		}
+		overlay, ok := ui.OVERLAY_NAMES[args[0]]
+		if !ok {
+			break
+		}
+		g.ui.State.Overlays ^= overlay
+		if g.ui.State.Overlays&overlay != 0 {
+			return []string{args[0] + " on"}
+		}
+		return []string{args[0] + " off"}
+	case "tool":
+		if len(args) != 1 {
+			break
+		}
+		tool, ok := CONSOLE_TOOLS[args[0]]
+		if !ok {
+			break
+		}
+		g.ui.State.Tool = tool
+		return nil
+	case "prefabs":
+		names := []string{}
+		for _, prefab := range g.prefabs {
+			names = append(names, prefab.Name)
+		}
+		if len(names) == 0 {
+			return []string{"no prefabs in " + g.prefabDir}
+		}
+		sort.Strings(names)
+		return []string{strings.Join(names, ", ")}
+	case "notes":
+		notes := g.board.Notes()
+		if len(notes) == 0 {
+			return []string{"no notes"}
+		}
+		lines := []string{}
+		for _, note := range notes {
+			lines = append(lines, fmt.Sprintf("%d,%d: %s", note.X, note.Y, note.Text))
+		}
+		return lines
+	case "maxheight":
+		if len(args) != 1 {
+			break
+		}
+		if reason := g.sharedBoardCommand(); reason != "" {
+			return []string{reason}
+		}
+		height, err := ui.ParseHeight(args[0])
+		if err != nil {
+			return []string{err.Error()}
+		}
+		if err := g.board.SetMaxHeight(height); err != nil {
+			return []string{err.Error()}
+		}
+		g.ui.State.LayerHeight = height
+		return nil
+	case "stats":
+		report := g.board.Report()
+		counts := map[string]int{}
+		for _, count := range report.Counts {
+			counts[count.Colour] += count.Count
+		}
+		colourCounts := []string{}
+		for _, colour := range g.board.Palette().Colours() {
+			colourCounts = append(colourCounts, fmt.Sprintf("%s %d", colour.Name, counts[colour.Name]))
+		}
+		return []string{
+			fmt.Sprintf("blocks %d, tallest %s/%s, edits %d", report.Blocks, ui.FormatHeight(report.Tallest()), ui.FormatHeight(g.board.MaxHeight()), len(g.board.Edits())),
+			strings.Join(colourCounts, ", "),
+		}
+	case "undo":
+		if !g.board.CanUndo() {
+			return []string{"nothing to undo"}
+		}
+		if err := g.board.Undo(); err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	case "export":
+		if len(args) != 1 {
+			break
+		}
+		var err error
+		switch strings.ToLower(filepath.Ext(args[0])) {
+		case ".vox":
+			err = g.board.SaveVox(args[0])
+		case ".obj":
+			err = g.board.SaveOBJ(args[0])
+		default:
+			return []string{"usage: " + usage}
+		}
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return []string{"exported " + args[0]}
+	case "report":
+		if len(args) != 1 {
+			break
+		}
+		if err := g.exportReport(args[0]); err != nil {
+			return []string{err.Error()}
+		}
+		return []string{"report saved to " + args[0]}
+	case "import":
+		if len(args) != 1 || strings.ToLower(filepath.Ext(args[0])) != ".vox" {
+			break
+		}
+		if reason := g.sharedBoardCommand(); reason != "" {
+			return []string{reason}
+		}
+		if err := g.board.LoadVox(args[0]); err != nil {
+			return []string{err.Error()}
+		}
+		g.ui.State.LayerHeight = g.board.MaxHeight()
+		return []string{"imported " + args[0]}
+	case "heightmap":
+		if len(args) < 1 || len(args) > 2 {
+			break
+		}
+		if reason := g.sharedBoardCommand(); reason != "" {
+			return []string{reason}
+		}
+		colourPath := ""
+		if len(args) == 2 {
+			colourPath = args[1]
+		}
+		if err := g.board.LoadHeightmap(args[0], colourPath); err != nil {
+			return []string{err.Error()}
+		}
+		g.ui.State.LayerHeight = g.board.MaxHeight()
+		w, h := g.board.Dimensions()
+		return []string{fmt.Sprintf("built %dx%d board from %s", w, h, args[0])}
+	case "rules":
+		names := []string{}
+		for _, rule := range g.board.Rules() {
+			names = append(names, rule.Name)
+		}
+		return []string{"active: " + strings.Join(names, ", "), "optional: " + strings.Join(objects.OptionalRuleNames(), ", ")}
+	case "rule":
+		if len(args) != 1 {
+			break
+		}
+		rule, ok := objects.OPTIONAL_RULES[args[0]]
+		if !ok {
+			return []string{fmt.Sprintf("unknown rule %q", args[0])}
+		}
+		if g.client != nil {
+			return []string{"rules cannot change during a shared session"}
+		}
+		if g.board.HasRule(rule.Name) {
+			g.board.RemoveRule(rule.Name)
+			return []string{rule.Name + " off"}
+		}
+		g.board.AddRule(rule)
+		return []string{rule.Name + " on"}
+	case "run":
+		if len(args) != 1 {
+			break
+		}
+		output := &strings.Builder{}
+		errs := script.RunFile(args[0], g.board, output)
+		lines := []string{}
+		if output.Len() > 0 {
+			lines = strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+		}
+		for _, err := range errs {
+			lines = append(lines, err.Error())
+		}
+		return lines
+	}
+	return []string{"usage: " + usage}
+}
+
+func (g *Game) fill(colour ui.BlockOperation, size ui.BlockSize) []string {
+	w, h := g.board.Dimensions()
+	result := objects.FillResult{}
+	g.board.BeginGroup()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Height includes edits in this fill that a shared session host has not relayed yet.
+			if height, _ := g.board.Height(x, y); height+size.GetHeight() > g.board.MaxHeight() {
+				result.TooTall++
+			} else if g.board.Place(x, y, size, colour) != nil {
+				result.Denied++
+			} else {
+				result.Changed++
+			}
+		}
+	}
+	g.board.EndGroup()
+	return []string{result.String()}
+}
+
+// colourNames lists the palette colours that can be typed as a single argument.
+func (g *Game) colourNames() []string {
+	names := []string{}
+	for _, colour := range g.board.Palette().Colours() {
+		if name := strings.ToLower(colour.Name); name != "" && !strings.ContainsAny(name, " \t") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -2,13 +2,14 @@ package game
 
 import (
 	"image/color"
+	"math"
 
 	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
 	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/network"
 	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
 	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
 
-	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/solarlune/resolv"
@@ -18,81 +19,208 @@ import (
 )
 
 type Game struct {
-	inputSystem  input.System
-	inputHandler *input.Handler
-	loader       *resource.Loader
-	background   *ebiten.Image
-	board        *objects.Board
-	ui           *ui.UI
-	cursor       *resolv.Object
+	inputSystem   input.System
+	inputHandler  *input.Handler
+	loader        *resource.Loader
+	background    *ebiten.Image
+	board         *objects.Board
+	ui            *ui.UI
+	cursor        *resolv.Object
+	settings      config.Settings
+	savedSettings config.Settings
+	overrides     Options
+	settingsPath  string
+	screenWidth   int
+	screenHeight  int
+	replayPath    string
+	replayPlayer  *objects.ReplayPlayer
+	server        *network.Server
+	client        *network.Client
+	texturePack   *assets.TexturePack
+	prefabDir     string
+	prefabs       map[string]*objects.Prefab
+	prefabEntries []ui.PrefabEntry
+	ticks         int
 }
 
-func NewGame() *Game {
-	g := &Game{}
-	g.inputSystem.Init(input.SystemConfig{
-		DevicesEnabled: input.AnyDevice,
-	})
-
-	g.inputHandler = g.inputSystem.NewHandler(0, ui.NewKeyMap())
+type Options struct {
+	ReplayPath    string
+	StartPlayback bool
+	HostAddr      string
+	JoinAddr      string
+	ScriptPath    string
+	Settings      config.Settings
+	SettingsPath  string
+	TexturePack   string
+	PrefabDir     string
+	// Scale and PixelPerfect override the settings for this run only, when set.
+	Scale        int
+	PixelPerfect bool
+}
 
+// newLoader reads assets from texturePack where it overrides them, if it is not nil.
+func newLoader(texturePack *assets.TexturePack) *resource.Loader {
 	audioContext := audio.NewContext(44100)
 	loader := resource.NewLoader(audioContext)
 	loader.OpenAssetFunc = assets.OpenAssetFunc
+	if texturePack != nil {
+		loader.OpenAssetFunc = texturePack.Open
+	}
 	assets.RegisterImageResources(loader)
+	assets.RegisterAudioResources(loader)
 	assets.RegisterFontResources(loader)
+	assets.RegisterRawResources(loader)
+	return loader
+}
+
+// newHeadlessLoader loads only the images and manifests a board needs, with no audio
+// context, so that boards can be rebuilt on machines without an audio device.
+func newHeadlessLoader() *resource.Loader {
+	loader := resource.NewLoader(nil)
+	loader.OpenAssetFunc = assets.OpenAssetFunc
+	assets.RegisterImageResources(loader)
+	assets.RegisterRawResources(loader)
+	return loader
+}
+
+func NewGame(options Options) (*Game, error) {
+	g := &Game{
+		settings:      options.RunSettings(),
+		savedSettings: options.Settings,
+		overrides:     options,
+		settingsPath:  options.SettingsPath,
+		screenWidth:   config.ScreenWidth,
+		screenHeight:  config.ScreenHeight,
+		replayPath:    options.ReplayPath,
+		prefabDir:     options.PrefabDir,
+	}
+	g.inputSystem.Init(input.SystemConfig{
+		DevicesEnabled: input.AnyDevice,
+	})
+
+	g.inputHandler = g.inputSystem.NewHandler(0, ui.NewKeyMap())
+
+	g.texturePack = openTexturePack(options.TexturePack)
+	// Broken assets are replaced by placeholders, so check them first to report them.
+	assetErrors := []error{}
+	checked := assets.CheckAssets(readAssets(g.texturePack))
+	for _, err := range checked.Problems {
+		assetErrors = append(assetErrors, err)
+	}
+	if _, err := objects.LoadTileset(readAssets(g.texturePack)); err != nil {
+		assetErrors = append(assetErrors, err)
+	}
+	loader := newLoader(g.texturePack)
+	loader.OpenAssetFunc = checked.Open
 	g.loader = loader
 
-	background := ebiten.NewImage(config.ScreenWidth, config.ScreenHeight)
-	background.Fill(color.RGBA{R: 21, G: 29, B: 40, A: 1}) // #151d28
-	g.background = background
+	g.background = newBackground(g.screenWidth, g.screenHeight, g.settings.BackgroundColour())
 
 	x, y := ebiten.CursorPosition()
 	g.cursor = resolv.NewObject(float64(x), float64(y), 1, 1)
 	g.board = objects.NewBoard(15, 15, 5, g.cursor, loader)
 
-	var viewModeChangedHandler widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if g.ui.State.Renderer == ui.ISOMETRIC {
-			g.ui.State.Renderer = ui.TWO_DIMENSIONAL
-		} else {
-			g.ui.State.Renderer = ui.ISOMETRIC
-		}
-	}
-	var blockSizeChangedHandler widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if g.ui.State.BlockSize == ui.HALF {
-			g.ui.State.BlockSize = ui.FULL
-		} else {
-			g.ui.State.BlockSize = ui.HALF
-		}
-	}
-
 	handlers := &ui.Handlers{
-		ViewToggleChangedHandler: &viewModeChangedHandler,
-		BlockSizeChangedHandler:  &blockSizeChangedHandler,
+		Playback: g.newPlaybackHandlers(),
+		Console:  g.newConsoleHandlers(),
+		Notes:    &ui.NoteHandlers{Save: g.board.SetNote},
+		Prefabs:  &ui.PrefabHandlers{Save: g.savePrefab},
+		Stats:    g.newStatsHandlers(),
 	}
 
-	g.ui = ui.NewUserInterface(handlers, loader)
+	g.ui = ui.NewUserInterface(handlers, g.settings, g.board.Palette(), g.board, loader)
 
-	return g
+	if err := g.startMultiplayer(options.HostAddr, options.JoinAddr); err != nil {
+		return nil, err
+	}
+	if g.server != nil {
+		g.ui.State.Info("Hosting session on " + g.server.Addr())
+	}
+	for _, err := range assetErrors {
+		g.ui.State.Error(err.Error())
+	}
+	if g.texturePack != nil {
+		g.reportTexturePackProblems()
+	}
+	g.loadPrefabs()
+	if options.StartPlayback {
+		g.startPlayback()
+	}
+	if options.ScriptPath != "" {
+		g.runScript(options.ScriptPath)
+	}
+
+	return g, nil
 }
 
 func (g *Game) Update() error {
 	g.inputSystem.Update()
+	g.board.SetScreenSize(g.screenWidth, g.screenHeight)
+	g.ui.SetScreenSize(g.screenWidth, g.screenHeight)
+	if g.inputHandler.ActionIsJustPressed(ui.ActionToggleConsole) && !g.ui.State.PaletteOpen {
+		g.ui.ToggleConsole()
+	}
+	g.updateSettings()
+	g.updateTexturePack()
+	g.syncMultiplayer()
+	g.updatePlayback()
+	g.board.SetStamp(g.prefabs[g.ui.State.Prefab])
 	g.board.Update(g.ui.State, g.inputHandler)
+	g.sendHover()
 	g.ui.Update()
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	if g.background == nil || g.background.Bounds().Dx() != g.screenWidth || g.background.Bounds().Dy() != g.screenHeight {
+		g.background = newBackground(g.screenWidth, g.screenHeight, g.settings.BackgroundColour())
+	}
 	screen.DrawImage(g.background, &ebiten.DrawImageOptions{})
-	g.ui.Draw(screen)
 	switch g.ui.State.Renderer {
 	case ui.ISOMETRIC:
 		g.board.RenderIso(screen)
 	case ui.TWO_DIMENSIONAL:
 		g.board.Render2D(screen)
+	case ui.ELEVATION:
+		g.board.RenderElevation(screen, g.ui.State)
 	}
+	g.ui.Draw(screen)
 }
 
+func newBackground(w int, h int, colour color.RGBA) *ebiten.Image {
+	background := ebiten.NewImage(w, h)
+	background.Fill(colour)
+	return background
+}
+
+// Layout is only there to satisfy ebiten.Game, as Ebiten calls LayoutF instead.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return config.ScreenWidth, config.ScreenHeight
+	w, h := g.LayoutF(float64(outsideWidth), float64(outsideHeight))
+	return int(math.Ceil(w)), int(math.Ceil(h))
+}
+
+// LayoutF makes the logical screen follow the window, divided by the pixel scale. Pixel
+// perfect scaling rounds the scale to a whole number of device pixels and sizes the screen
+// to fill the window exactly at that scale, so Ebiten itself maps the screen and the cursor
+// without stretching; a pixel cut off at the right or bottom edge is cropped.
+func (g *Game) LayoutF(outsideWidth, outsideHeight float64) (float64, float64) {
+	scale := float64(g.settings.Scale)
+	w, h := math.Floor(outsideWidth/scale), math.Floor(outsideHeight/scale)
+	if g.settings.PixelPerfect {
+		deviceScale := ebiten.DeviceScaleFactor()
+		scale = math.Max(1, math.Round(scale*deviceScale)) / deviceScale
+		w, h = outsideWidth/scale, outsideHeight/scale
+	}
+	w, h = math.Max(w, config.MinScreenWidth), math.Max(h, config.MinScreenHeight)
+	g.screenWidth, g.screenHeight = int(math.Ceil(w)), int(math.Ceil(h))
+	return w, h
+}
+
+// DrawFinalScreen smooths the screen when it is stretched by a fraction of a pixel.
+func (g *Game) DrawFinalScreen(screen ebiten.FinalScreen, offscreen *ebiten.Image, geoM ebiten.GeoM) {
+	drawOpts := &ebiten.DrawImageOptions{GeoM: geoM}
+	if scale := geoM.Element(0, 0); scale != math.Floor(scale) {
+		drawOpts.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(offscreen, drawOpts)
 }
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"log"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/network"
+)
+
+// startMultiplayer hosts a session on hostAddr, or joins the one at joinAddr. A host
+// also joins its own server over loopback so that every instance applies edits in the
+// order the server relays them.
+func (g *Game) startMultiplayer(hostAddr string, joinAddr string) error {
+	if hostAddr != "" {
+		server, err := network.NewServer(hostAddr, g.board)
+		if err != nil {
+			return err
+		}
+		g.server = server
+		joinAddr = server.Addr()
+		log.Printf("hosting session on %s", joinAddr)
+	}
+	if joinAddr == "" {
+		return nil
+	}
+
+	client, err := network.Dial(joinAddr)
+	if err != nil {
+		return err
+	}
+	g.client = client
+	g.board.SetEditHandler(client.SendEdit)
+	return nil
+}
+
+func (g *Game) syncMultiplayer() {
+	g.ui.State.SharedSession = g.client != nil
+	if g.client == nil {
+		return
+	}
+	connected := g.client.Sync(g.board)
+	for _, reason := range g.client.Rejected() {
+		g.ui.State.Warn("Edit rejected: " + reason)
+	}
+	if !connected {
+		if err := g.client.Err(); err != nil {
+			g.ui.State.Error("Lost connection to host: " + err.Error())
+		} else {
+			g.ui.State.Error("Lost connection to host")
+		}
+		g.board.SetEditHandler(nil)
+		g.board.SetRemoteHovers(nil)
+		g.client = nil
+	}
+}
+
+func (g *Game) sendHover() {
+	if g.client != nil {
+		g.client.SendHover(g.board.HoveredStack())
+	}
+}
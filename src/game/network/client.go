@@ -0,0 +1,189 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+)
+
+// Client is one participant in a session. Local edits are sent to the host rather than
+// applied directly, and only take effect once the host relays them back through Sync.
+type Client struct {
+	conn     net.Conn
+	encoder  *json.Encoder
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	inbox    []Message
+	closed   bool
+	err      error
+	id       int
+	hovers   map[int]Message
+	rejected []string
+	hoverX   int
+	hoverY   int
+	hovering bool
+}
+
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		hovers:  map[int]Message{},
+	}
+	go c.read()
+	return c, nil
+}
+
+func (c *Client) read() {
+	decoder := json.NewDecoder(bufio.NewReader(c.conn))
+	for {
+		var message Message
+		if err := decoder.Decode(&message); err != nil {
+			c.mu.Lock()
+			c.closed = true
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Lock()
+		c.inbox = append(c.inbox, message)
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) send(message Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.encoder.Encode(message)
+}
+
+func (c *Client) SendEdit(edit objects.Edit) {
+	c.send(Message{Kind: EDIT, Edit: &edit})
+}
+
+// SendHover reports the local hovered cell to the other peers when it changes.
+func (c *Client) SendHover(x int, y int, hovered bool) {
+	if x == c.hoverX && y == c.hoverY && hovered == c.hovering {
+		return
+	}
+	c.hoverX, c.hoverY, c.hovering = x, y, hovered
+	c.send(Message{Kind: HOVER, X: x, Y: y, Hovered: hovered})
+}
+
+// Poll returns and clears every message received from the host since the last call.
+func (c *Client) Poll() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	messages := c.inbox
+	c.inbox = nil
+	return messages
+}
+
+// Sync applies pending host messages to board. It reports false once the connection
+// to the host has been lost, or the host has sent a board that cannot be built.
+func (c *Client) Sync(board *objects.Board) bool {
+	for _, message := range c.Poll() {
+		switch message.Kind {
+		case WELCOME:
+			c.id = message.Peer
+			if err := objects.CheckBoardSize(message.Width, message.Height, message.Depth); err != nil {
+				c.fail(fmt.Errorf("host board: %w", err))
+				return false
+			}
+			if err := objects.CheckPalette(message.Palette); err != nil {
+				c.fail(fmt.Errorf("host palette: %w", err))
+				return false
+			}
+			board.Reset(message.Width, message.Height, message.Depth)
+			if err := board.SetMaxHeight(message.MaxHeight); err != nil {
+				c.fail(fmt.Errorf("host board: %w", err))
+				return false
+			}
+			if err := board.SetOptionalRules(message.Rules); err != nil {
+				c.fail(fmt.Errorf("host rules: %w", err))
+				return false
+			}
+			if len(message.Palette) > 0 {
+				board.Palette().Replace(message.Palette)
+			}
+			for _, edit := range message.Edits {
+				c.apply(board, edit)
+			}
+			c.hovers = map[int]Message{}
+			for _, hover := range message.Hovers {
+				c.hovers[hover.Peer] = hover
+			}
+		case EDIT:
+			if message.Edit == nil {
+				c.rejected = append(c.rejected, "host sent an edit without a block")
+				break
+			}
+			c.apply(board, *message.Edit)
+		case HOVER:
+			c.hovers[message.Peer] = message
+		case LEAVE:
+			delete(c.hovers, message.Peer)
+		case REJECT:
+			c.rejected = append(c.rejected, message.Reason)
+		}
+	}
+	board.SetRemoteHovers(c.remoteHovers())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.closed
+}
+
+// apply restamps edit with the local tick so recorded sessions stay in arrival order.
+func (c *Client) apply(board *objects.Board, edit objects.Edit) {
+	edit.Tick = board.Tick()
+	board.Apply(edit)
+}
+
+// Rejected returns and clears why the host turned down local edits since the last call.
+func (c *Client) Rejected() []string {
+	rejected := c.rejected
+	c.rejected = nil
+	return rejected
+}
+
+// fail drops the connection to the host because of err.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	c.err = err
+	c.closed = true
+	c.mu.Unlock()
+	c.conn.Close()
+}
+
+// Err returns why the client dropped the connection itself, or nil.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) remoteHovers() []objects.RemoteHover {
+	hovers := []objects.RemoteHover{}
+	for peer, hover := range c.hovers {
+		if hover.Hovered && peer != c.id {
+			hovers = append(hovers, objects.RemoteHover{X: hover.X, Y: hover.Y, Colour: peerColour(peer)})
+		}
+	}
+	return hovers
+}
+
+func (c *Client) ID() int {
+	return c.id
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
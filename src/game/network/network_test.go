@@ -0,0 +1,328 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/solarlune/resolv"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const SYNC_TIMEOUT = 5 * time.Second
+
+func newTestBoard(w int, h int, d int) *objects.Board {
+	loader := resource.NewLoader(nil)
+	loader.OpenAssetFunc = assets.OpenAssetFunc
+	assets.RegisterImageResources(loader)
+	assets.RegisterRawResources(loader)
+	return objects.NewBoard(w, h, d, resolv.NewObject(0, 0, 1, 1), loader)
+}
+
+type testPeer struct {
+	client *Client
+	board  *objects.Board
+}
+
+// startSession hosts a w x h x d session on loopback and joins it with n clients, each
+// with its own board.
+func startSession(t *testing.T, w int, h int, d int, n int) []*testPeer {
+	t.Helper()
+	return hostSession(t, newTestBoard(w, h, d), n)
+}
+
+// hostSession hosts a session the size of board on loopback and joins it with n clients.
+func hostSession(t *testing.T, board *objects.Board, n int) []*testPeer {
+	t.Helper()
+	server, err := NewServer("127.0.0.1:0", board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	peers := []*testPeer{}
+	for i := 0; i < n; i++ {
+		client, err := Dial(server.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { client.Close() })
+		peer := &testPeer{client: client, board: newTestBoard(1, 1, 1)}
+		waitFor(t, peers, "welcome", func() bool {
+			peer.client.Sync(peer.board)
+			return peer.client.ID() != 0
+		})
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// waitFor syncs every peer until done reports true.
+func waitFor(t *testing.T, peers []*testPeer, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(SYNC_TIMEOUT)
+	for {
+		for _, peer := range peers {
+			if !peer.client.Sync(peer.board) {
+				t.Fatalf("peer %d lost its connection waiting for %s", peer.client.ID(), what)
+			}
+		}
+		if done() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// withoutTicks drops the local ticks, which each peer stamps on arrival.
+func withoutTicks(edits []objects.Edit) []objects.Edit {
+	stripped := make([]objects.Edit, len(edits))
+	for i, edit := range edits {
+		edit.Tick = 0
+		stripped[i] = edit
+	}
+	return stripped
+}
+
+func assertSameBoards(t *testing.T, peers []*testPeer) {
+	t.Helper()
+	first := peers[0].board
+	for _, peer := range peers[1:] {
+		if !reflect.DeepEqual(withoutTicks(peer.board.Edits()), withoutTicks(first.Edits())) {
+			t.Errorf("peer %d applied edits %v, peer %d applied %v", peer.client.ID(), peer.board.Edits(), peers[0].client.ID(), first.Edits())
+		}
+		if !reflect.DeepEqual(peer.board.Heights(), first.Heights()) {
+			t.Errorf("peer %d has heights %v, peer %d has %v", peer.client.ID(), peer.board.Heights(), peers[0].client.ID(), first.Heights())
+		}
+		w, h := first.Dimensions()
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if a, b := topBlock(peer.board, x, y), topBlock(first, x, y); a != b {
+					t.Errorf("peer %d has %v on top at (%d, %d), peer %d has %v", peer.client.ID(), a, x, y, peers[0].client.ID(), b)
+				}
+			}
+		}
+	}
+}
+
+func topBlock(board *objects.Board, x int, y int) [2]int {
+	colour, height := board.TopBlock(x, y)
+	return [2]int{int(colour), height}
+}
+
+func place(x int, y int, size ui.BlockSize, colour ui.BlockOperation) objects.Edit {
+	return objects.Edit{X: x, Y: y, Operation: objects.PLACE, Size: size, Colour: colour}
+}
+
+func TestEditsFromTwoClientsArriveInTheSameOrder(t *testing.T) {
+	peers := startSession(t, 4, 4, 5, 2)
+	colours := []ui.BlockOperation{ui.PLACE_BLUE, ui.PLACE_RED}
+
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(client *Client, colour ui.BlockOperation) {
+			defer wg.Done()
+			for n := 0; n < 16; n++ {
+				client.SendEdit(place(n%4, n/4, ui.FULL, colour))
+			}
+		}(peer.client, colours[i])
+	}
+	wg.Wait()
+
+	waitFor(t, peers, "32 edits", func() bool {
+		return len(peers[0].board.Edits()) == 32 && len(peers[1].board.Edits()) == 32
+	})
+	assertSameBoards(t, peers)
+	for _, row := range peers[0].board.Heights() {
+		for _, height := range row {
+			if height != 2*ui.FULL.GetHeight() {
+				t.Fatalf("every stack should hold one block from each client, got heights %v", peers[0].board.Heights())
+			}
+		}
+	}
+}
+
+func TestEditsKeepTheOrderEachClientSentThem(t *testing.T) {
+	peers := startSession(t, 2, 2, 5, 2)
+	peers[0].client.SendEdit(place(0, 0, ui.QUARTER, ui.PLACE_BLUE))
+	peers[1].client.SendEdit(place(1, 1, ui.HALF, ui.PLACE_RED))
+	peers[0].client.SendEdit(place(0, 0, ui.FULL, ui.PLACE_RED))
+	peers[1].client.SendEdit(place(1, 1, ui.FULL, ui.PLACE_YELLOW))
+	peers[0].client.SendEdit(objects.Edit{X: 0, Y: 0, Operation: objects.DELETE, Size: ui.FULL, Colour: ui.PLACE_RED})
+
+	waitFor(t, peers, "5 edits", func() bool {
+		return len(peers[0].board.Edits()) == 5 && len(peers[1].board.Edits()) == 5
+	})
+	assertSameBoards(t, peers)
+
+	// The host may interleave the two clients, but never reorders one client's edits.
+	fromFirst := []objects.Edit{}
+	for _, edit := range withoutTicks(peers[1].board.Edits()) {
+		if edit.X == 0 {
+			fromFirst = append(fromFirst, edit)
+		}
+	}
+	want := []objects.Edit{
+		place(0, 0, ui.QUARTER, ui.PLACE_BLUE),
+		place(0, 0, ui.FULL, ui.PLACE_RED),
+		{X: 0, Y: 0, Operation: objects.DELETE, Size: ui.FULL, Colour: ui.PLACE_RED},
+	}
+	if !reflect.DeepEqual(fromFirst, want) {
+		t.Errorf("edits from the first client arrived as %v, want %v", fromFirst, want)
+	}
+}
+
+func TestHostRejectsEditsOverTheMaxHeight(t *testing.T) {
+	peers := startSession(t, 3, 3, 2, 2)
+
+	var wg sync.WaitGroup
+	for _, peer := range peers {
+		wg.Add(1)
+		go func(client *Client) {
+			defer wg.Done()
+			for n := 0; n < 4; n++ {
+				client.SendEdit(place(1, 1, ui.FULL, ui.PLACE_BLUE))
+			}
+		}(peer.client)
+	}
+	wg.Wait()
+
+	rejected := 0
+	waitFor(t, peers, "6 rejections", func() bool {
+		for _, peer := range peers {
+			rejected += len(peer.client.Rejected())
+		}
+		return rejected == 6
+	})
+	// A final edit from each client makes sure nothing else is still on its way.
+	for _, peer := range peers {
+		peer.client.SendEdit(place(0, 0, ui.FULL, ui.PLACE_RED))
+	}
+	waitFor(t, peers, "4 edits", func() bool {
+		return len(peers[0].board.Edits()) == 4 && len(peers[1].board.Edits()) == 4
+	})
+	assertSameBoards(t, peers)
+	if height := peers[0].board.Heights()[1][1]; height != 2*ui.FULL.GetHeight() {
+		t.Errorf("stack at (1, 1) is %d high, want two full blocks", height)
+	}
+}
+
+func TestClientsTakeTheHostMaxHeight(t *testing.T) {
+	board := newTestBoard(3, 3, 2)
+	if err := board.SetMaxHeight(3 * ui.FULL.GetHeight()); err != nil {
+		t.Fatal(err)
+	}
+	peers := hostSession(t, board, 2)
+	for i, peer := range peers {
+		if got := peer.board.MaxHeight(); got != 3*ui.FULL.GetHeight() {
+			t.Errorf("client %d has a max height of %d, want three full blocks", i, got)
+		}
+	}
+}
+
+func TestClientsTakeTheHostRulesAndPalette(t *testing.T) {
+	board := newTestBoard(3, 3, 2)
+	board.AddRule(objects.HALF_ON_TOP_RULE)
+	board.Palette().Rename(ui.PLACE_RED, "brick")
+	peers := hostSession(t, board, 2)
+	for i, peer := range peers {
+		if !peer.board.HasRule(objects.HALF_ON_TOP_RULE.Name) {
+			t.Errorf("client %d does not have the host's %s rule", i, objects.HALF_ON_TOP_RULE.Name)
+		}
+		if name := peer.board.Palette().Name(ui.PLACE_RED); name != "brick" {
+			t.Errorf("client %d calls the host's brick colour %q", i, name)
+		}
+	}
+}
+
+func TestHostRejectsDeletesOfABlockNoLongerOnTop(t *testing.T) {
+	peers := startSession(t, 2, 2, 5, 2)
+	peers[0].client.SendEdit(place(0, 0, ui.FULL, ui.PLACE_BLUE))
+	waitFor(t, peers, "the first block", func() bool {
+		return len(peers[1].board.Edits()) == 1
+	})
+	peers[1].client.SendEdit(place(0, 0, ui.HALF, ui.PLACE_RED))
+	waitFor(t, peers, "the second block", func() bool {
+		return len(peers[0].board.Edits()) == 2
+	})
+
+	// The first client undoes its block after the second client has built on it.
+	peers[0].client.SendEdit(objects.Edit{X: 0, Y: 0, Operation: objects.DELETE, Size: ui.FULL, Colour: ui.PLACE_BLUE})
+	waitFor(t, peers, "the rejection", func() bool {
+		return len(peers[0].client.Rejected()) == 1
+	})
+	if colour, _ := peers[1].board.TopBlock(0, 0); colour != ui.PLACE_RED {
+		t.Errorf("second client's block was removed, top is now %d", colour)
+	}
+	assertSameBoards(t, peers)
+}
+
+// fakeHost accepts one client on loopback and sends it messages, as a faulty host would.
+func fakeHost(t *testing.T, messages ...Message) *Client {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		encoder := json.NewEncoder(conn)
+		for _, message := range messages {
+			encoder.Encode(message)
+		}
+	}()
+	client, err := Dial(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClientsReportEditsWithoutABlock(t *testing.T) {
+	client := fakeHost(t,
+		Message{Kind: WELCOME, Peer: 1, Width: 2, Height: 2, Depth: 2, MaxHeight: 2 * ui.FULL.GetHeight()},
+		Message{Kind: EDIT, Peer: 1, Seq: 1},
+	)
+	peers := []*testPeer{{client: client, board: newTestBoard(1, 1, 1)}}
+	var rejected []string
+	waitFor(t, peers, "the rejection", func() bool {
+		rejected = append(rejected, client.Rejected()...)
+		return len(rejected) > 0
+	})
+	if edits := peers[0].board.Edits(); len(edits) != 0 {
+		t.Errorf("client applied %v", edits)
+	}
+}
+
+func TestClientsRefuseHostBoardsThatAreTooBig(t *testing.T) {
+	client := fakeHost(t, Message{Kind: WELCOME, Peer: 1, Width: 100000, Height: 100000, Depth: 1, MaxHeight: ui.FULL.GetHeight()})
+	board := newTestBoard(1, 1, 1)
+	deadline := time.Now().Add(SYNC_TIMEOUT)
+	for client.Sync(board) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the client to drop the host")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if client.Err() == nil {
+		t.Error("client dropped the host without a reason")
+	}
+	if w, h := board.Dimensions(); w != 1 || h != 1 {
+		t.Errorf("client board was resized to %dx%d", w, h)
+	}
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"image/color"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+type MessageKind int
+
+const (
+	WELCOME MessageKind = iota
+	EDIT
+	HOVER
+	LEAVE
+	REJECT
+)
+
+// Message is sent as one JSON object per line in both directions. Clients send EDIT and
+// HOVER requests; the host stamps them with the sender's peer id and an edit sequence
+// number before relaying them, or answers an invalid edit with a REJECT to its sender.
+type Message struct {
+	Kind      MessageKind
+	Peer      int
+	Seq       int                `json:",omitempty"`
+	Edit      *objects.Edit      `json:",omitempty"`
+	X         int                `json:",omitempty"`
+	Y         int                `json:",omitempty"`
+	Hovered   bool               `json:",omitempty"`
+	Width     int                `json:",omitempty"`
+	Height    int                `json:",omitempty"`
+	Depth     int                `json:",omitempty"`
+	MaxHeight int                `json:",omitempty"`
+	Palette   []ui.PaletteColour `json:",omitempty"`
+	Rules     []string           `json:",omitempty"`
+	Edits     []objects.Edit     `json:",omitempty"`
+	Hovers    []Message          `json:",omitempty"`
+	Reason    string             `json:",omitempty"`
+}
+
+var PEER_COLOURS = []color.RGBA{
+	{R: 222, G: 158, B: 65, A: 255}, // #de9e41
+	{R: 198, G: 81, B: 151, A: 255}, // #c65197
+	{R: 117, G: 167, B: 67, A: 255}, // #75a743
+	{R: 79, G: 143, B: 186, A: 255}, // #4f8fba
+	{R: 165, G: 48, B: 48, A: 255},  // #a53030
+	{R: 122, G: 54, B: 123, A: 255}, // #7a367b
+}
+
+func peerColour(peer int) color.RGBA {
+	return PEER_COLOURS[peer%len(PEER_COLOURS)]
+}
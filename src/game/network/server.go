@@ -0,0 +1,215 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+)
+
+// MAX_PEER_QUEUE is how many messages may wait for a peer before the host gives up on it.
+const MAX_PEER_QUEUE = 4096
+
+// peer is one connection to the host. Messages are queued and written by the peer's
+// own goroutine, so a slow peer only holds itself up.
+type peer struct {
+	id     int
+	conn   net.Conn
+	hover  Message
+	mu     sync.Mutex
+	queue  []Message
+	ready  chan struct{}
+	closed chan struct{}
+}
+
+func newPeer(id int, conn net.Conn) *peer {
+	p := &peer{id: id, conn: conn, ready: make(chan struct{}, 1), closed: make(chan struct{})}
+	go p.write()
+	return p
+}
+
+// send queues message, disconnecting the peer if it has fallen too far behind.
+func (p *peer) send(message Message) {
+	p.mu.Lock()
+	if len(p.queue) >= MAX_PEER_QUEUE {
+		p.mu.Unlock()
+		p.conn.Close()
+		return
+	}
+	p.queue = append(p.queue, message)
+	p.mu.Unlock()
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (p *peer) write() {
+	encoder := json.NewEncoder(p.conn)
+	for {
+		select {
+		case <-p.ready:
+		case <-p.closed:
+			return
+		}
+		p.mu.Lock()
+		messages := p.queue
+		p.queue = nil
+		p.mu.Unlock()
+		for _, message := range messages {
+			if err := encoder.Encode(message); err != nil {
+				p.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (p *peer) close() {
+	close(p.closed)
+	p.conn.Close()
+}
+
+// Server is the host of a co-editing session. It owns the authoritative edit log, checks
+// each edit against its own copy of the board and relays the valid ones to all peers,
+// including the sender, in the order it received them.
+type Server struct {
+	listener  net.Listener
+	mu        sync.Mutex
+	peers     map[int]*peer
+	nextPeer  int
+	width     int
+	height    int
+	depth     int
+	edits     []objects.Edit
+	validator *objects.Validator
+}
+
+// NewServer hosts an empty session the size of board, using its rules, palette and max
+// height to check edits.
+func NewServer(addr string, board *objects.Board) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	replay := board.Replay()
+	s := &Server{
+		listener:  listener,
+		peers:     map[int]*peer{},
+		width:     replay.Width,
+		height:    replay.Height,
+		depth:     replay.Depth,
+		validator: board.NewValidator(replay.Width, replay.Height),
+	}
+	go s.accept()
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for _, p := range s.peers {
+		p.conn.Close()
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) join(conn net.Conn) *peer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextPeer++
+	p := newPeer(s.nextPeer, conn)
+	welcome := Message{
+		Kind:      WELCOME,
+		Peer:      p.id,
+		Seq:       len(s.edits),
+		Width:     s.width,
+		Height:    s.height,
+		Depth:     s.depth,
+		MaxHeight: s.validator.MaxHeight(),
+		Palette:   s.validator.Palette(),
+		Rules:     s.validator.OptionalRules(),
+		Edits:     append([]objects.Edit{}, s.edits...),
+	}
+	for _, other := range s.peers {
+		if other.hover.Hovered {
+			welcome.Hovers = append(welcome.Hovers, other.hover)
+		}
+	}
+	p.send(welcome)
+	s.peers[p.id] = p
+	return p
+}
+
+func (s *Server) leave(p *peer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.peers, p.id)
+	p.close()
+	s.broadcast(Message{Kind: LEAVE, Peer: p.id}, p.id)
+}
+
+// broadcast queues message for every peer except skip. Callers must hold s.mu so that
+// every peer sees messages in the same order.
+func (s *Server) broadcast(message Message, skip int) {
+	for _, p := range s.peers {
+		if p.id != skip {
+			p.send(message)
+		}
+	}
+}
+
+func (s *Server) handle(p *peer, message Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message.Peer = p.id
+	switch message.Kind {
+	case EDIT:
+		if message.Edit == nil {
+			return
+		}
+		if err := s.validator.Apply(*message.Edit); err != nil {
+			p.send(Message{Kind: REJECT, Peer: p.id, Reason: err.Error()})
+			return
+		}
+		s.edits = append(s.edits, *message.Edit)
+		message.Seq = len(s.edits)
+		s.broadcast(message, 0)
+	case HOVER:
+		p.hover = message
+		s.broadcast(message, p.id)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	p := s.join(conn)
+	defer s.leave(p)
+
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var message Message
+		if err := decoder.Decode(&message); err != nil {
+			return
+		}
+		s.handle(p, message)
+	}
+}
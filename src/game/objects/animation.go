@@ -0,0 +1,132 @@
+package objects
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+type TileAnimationKind int
+
+const (
+	DROP_IN TileAnimationKind = iota
+	SHRINK_OUT
+)
+
+const (
+	DROP_IN_TICKS    = 18
+	DROP_IN_HEIGHT   = 24
+	SHRINK_OUT_TICKS = 12
+)
+
+type TileAnimation struct {
+	kind TileAnimationKind
+	tick int
+}
+
+func newTileAnimation(kind TileAnimationKind) *TileAnimation {
+	return &TileAnimation{kind: kind}
+}
+
+func (animation *TileAnimation) duration() int {
+	if animation.kind == SHRINK_OUT {
+		return SHRINK_OUT_TICKS
+	}
+	return DROP_IN_TICKS
+}
+
+func (animation *TileAnimation) progress() float64 {
+	return float64(animation.tick) / float64(animation.duration())
+}
+
+func (animation *TileAnimation) isDone() bool {
+	return animation.tick >= animation.duration()
+}
+
+// transform returns the vertical offset, scale and alpha of the tile at the current tick.
+func (animation *TileAnimation) transform() (float64, float64, float64) {
+	t := animation.progress()
+	if animation.kind == SHRINK_OUT {
+		return 0, 1 - t, 1 - t
+	}
+	return -DROP_IN_HEIGHT * (1 - easeOutBounce(t)), 1, math.Min(1, t*3)
+}
+
+func easeOutBounce(t float64) float64 {
+	const n = 7.5625
+	const d = 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// tileDrawOptions positions a sprite at point, scaling about its bottom centre so
+// that shrinking tiles sink into the stack below them. Blocks are tinted with colour.
+func tileDrawOptions(tile *Tile, sprite *ebiten.Image, point *Point, camera *Point, c color.RGBA) *ebiten.DrawImageOptions {
+	drawOpts := &ebiten.DrawImageOptions{}
+	if tile.height != ui.FLAT {
+		drawOpts.ColorM.Scale(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255, 1)
+	}
+	if tile.animation != nil {
+		offsetY, scale, alpha := tile.animation.transform()
+		w, h := sprite.Size()
+		drawOpts.GeoM.Translate(-float64(w)/2, -float64(h))
+		drawOpts.GeoM.Scale(scale, scale)
+		drawOpts.GeoM.Translate(float64(w)/2, float64(h)+offsetY)
+		drawOpts.ColorScale.ScaleAlpha(float32(alpha))
+	}
+	drawOpts.GeoM.Translate(point.X-camera.X, point.Y-camera.Y)
+	return drawOpts
+}
+
+func (ts *TileStack) updateAnimations() {
+	for _, tile := range ts.stack {
+		if tile.animation != nil {
+			tile.animation.tick++
+			if tile.animation.isDone() {
+				tile.animation = nil
+			}
+		}
+	}
+
+	removed := ts.removed[:0]
+	for _, tile := range ts.removed {
+		tile.animation.tick++
+		if !tile.animation.isDone() {
+			removed = append(removed, tile)
+		}
+	}
+	ts.removed = removed
+}
+
+func (ts *TileStack) clearAnimations() {
+	for _, tile := range ts.stack {
+		tile.animation = nil
+	}
+	ts.removed = nil
+}
+
+func (b *Board) updateAnimations(enabled bool) {
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			if enabled {
+				tileStack.updateAnimations()
+			} else {
+				tileStack.clearAnimations()
+			}
+		}
+	}
+	b.animationsEnabled = enabled
+}
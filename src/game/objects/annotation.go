@@ -0,0 +1,220 @@
+package objects
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	input "github.com/quasilyte/ebitengine-input"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const (
+	MAX_NOTE_LENGTH = 200
+	NOTE_WRAP       = 32
+	NOTE_ICON_SIZE  = 7
+)
+
+var (
+	NOTE_COLOUR       = color.RGBA{R: 222, G: 158, B: 65, A: 255}          // #de9e41
+	MEASURE_COLOUR    = color.RGBA{R: 164, G: 221, B: 219, A: 255}         // #a4dddb
+	NOTE_PANEL_COLOUR = fade(color.RGBA{R: 21, G: 29, B: 40, A: 255}, 0.9) // #151d28
+)
+
+// Note is text pinned to the cell at X, Y.
+type Note struct {
+	X    int
+	Y    int
+	Text string
+}
+
+// Measure is a span between two stacks picked with the measure tool.
+type Measure struct {
+	start *TileStack
+	end   *TileStack
+}
+
+// String gives the Manhattan and Euclidean distances in cells and the height of the
+// end above the start in blocks.
+func (measure *Measure) String() string {
+	dx, dy := measure.end.x-measure.start.x, measure.end.y-measure.start.y
+	height := measure.end.currentHeight - measure.start.currentHeight
+	sign := "+"
+	if height < 0 {
+		sign, height = "-", -height
+	}
+	manhattan := abs(dx) + abs(dy)
+	euclidean := math.Hypot(float64(dx), float64(dy))
+	return fmt.Sprintf("MAN %d EUC %.2f HGT %s%s", manhattan, euclidean, sign, ui.FormatHeight(height))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Note returns the text pinned to x, y, or "" if there is none.
+func (b *Board) Note(x int, y int) string {
+	return b.notes[[2]int{x, y}]
+}
+
+// SetNote pins text to x, y, cut to MAX_NOTE_LENGTH bytes. Empty text removes the note.
+func (b *Board) SetNote(x int, y int, text string) {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return
+	}
+	if text == "" {
+		delete(b.notes, [2]int{x, y})
+		return
+	}
+	if len(text) > MAX_NOTE_LENGTH {
+		text = strings.ToValidUTF8(text[:MAX_NOTE_LENGTH], "")
+	}
+	b.notes[[2]int{x, y}] = text
+}
+
+// Notes lists the notes on the board by row, then column.
+func (b *Board) Notes() []Note {
+	notes := make([]Note, 0, len(b.notes))
+	for cell, text := range b.notes {
+		notes = append(notes, Note{X: cell[0], Y: cell[1], Text: text})
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		if notes[i].Y != notes[j].Y {
+			return notes[i].Y < notes[j].Y
+		}
+		return notes[i].X < notes[j].X
+	})
+	return notes
+}
+
+// SetNotes replaces every note on the board.
+func (b *Board) SetNotes(notes []Note) {
+	b.notes = make(map[[2]int]string, len(notes))
+	for _, note := range notes {
+		b.SetNote(note.X, note.Y, note.Text)
+	}
+}
+
+func (b *Board) dropNotesOutside(w int, h int) {
+	for cell := range b.notes {
+		if cell[0] >= w || cell[1] >= h {
+			delete(b.notes, cell)
+		}
+	}
+}
+
+// updateMeasure measures from the stack the select button was pressed on to the one it
+// is held over. The delete button clears the measurement.
+func (b *Board) updateMeasure(tileStack *TileStack, handler *input.Handler) {
+	switch {
+	case handler.ActionIsJustPressed(ui.ActionSelect):
+		b.measure = &Measure{start: tileStack, end: tileStack}
+	case handler.ActionIsPressed(ui.ActionSelect) && b.measure != nil:
+		b.measure.end = tileStack
+	case handler.ActionIsJustPressed(ui.ActionDelete):
+		b.measure = nil
+	}
+}
+
+// updateNote opens the note editor for the clicked stack, or removes its note.
+func (b *Board) updateNote(tileStack *TileStack, state *ui.State, handler *input.Handler) {
+	if handler.ActionIsJustPressed(ui.ActionSelect) {
+		state.EditNote(tileStack.x, tileStack.y, b.Note(tileStack.x, tileStack.y))
+	} else if handler.ActionIsJustPressed(ui.ActionDelete) && b.Note(tileStack.x, tileStack.y) != "" {
+		b.SetNote(tileStack.x, tileStack.y, "")
+		state.PlaySound(assets.AudioDelete)
+	}
+}
+
+// renderAnnotations draws note icons, the text of the hovered stack's note and the
+// measurement. anchor gives the screen position of a stack's top, if it is on screen.
+func (b *Board) renderAnnotations(screen *ebiten.Image, anchor func(*TileStack) (float64, float64, bool)) {
+	var hovered *TileStack
+	for _, note := range b.Notes() {
+		tileStack := b.data[note.Y][note.X]
+		x, y, ok := anchor(tileStack)
+		if !ok {
+			continue
+		}
+		left, top := float32(x)-NOTE_ICON_SIZE/2, float32(y)-NOTE_ICON_SIZE-2
+		vector.DrawFilledRect(screen, left, top, NOTE_ICON_SIZE, NOTE_ICON_SIZE, NOTE_COLOUR, false)
+		vector.StrokeRect(screen, left+0.5, top+0.5, NOTE_ICON_SIZE-1, NOTE_ICON_SIZE-1, 1, elevationOutline, false)
+		if tileStack.isHovered {
+			hovered = tileStack
+		}
+	}
+	if b.measure != nil {
+		x0, y0, startOK := anchor(b.measure.start)
+		x1, y1, endOK := anchor(b.measure.end)
+		if startOK && endOK {
+			vector.StrokeLine(screen, float32(x0), float32(y0), float32(x1), float32(y1), 1, MEASURE_COLOUR, false)
+		}
+		if endOK {
+			face := b.loader.LoadFont(assets.FontDefault).Face
+			drawLabel(screen, b.measure.String(), face, x1, y1-16)
+		}
+	}
+	if hovered != nil {
+		x, y, _ := anchor(hovered)
+		b.renderNoteText(screen, b.Note(hovered.x, hovered.y), x+NOTE_ICON_SIZE, y-NOTE_ICON_SIZE-2)
+	}
+}
+
+// renderNoteText draws text wrapped onto a panel with its top left corner at x, y.
+func (b *Board) renderNoteText(screen *ebiten.Image, note string, x float64, y float64) {
+	face := b.loader.LoadFont(assets.FontDefault).Face
+	lines := wrapText(note, NOTE_WRAP)
+	lineHeight := face.Metrics().Height.Ceil()
+	width := 0
+	for _, line := range lines {
+		if w := text.BoundString(face, line).Dx(); w > width {
+			width = w
+		}
+	}
+	const padding = 3
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width+2*padding), float32(len(lines)*lineHeight+2*padding), NOTE_PANEL_COLOUR, false)
+	ascent := face.Metrics().Ascent.Ceil()
+	for i, line := range lines {
+		text.Draw(screen, line, face, int(x)+padding, int(y)+padding+ascent+i*lineHeight, LABEL_COLOUR)
+	}
+}
+
+// wrapText splits s into lines of at most width characters, breaking between words
+// where it can.
+func wrapText(s string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for len([]rune(word)) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -2,6 +2,8 @@ package objects
 
 import (
 	"fmt"
+	"image/color"
+	"math"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -23,38 +25,74 @@ type Tile struct {
 	sprite2D  *ebiten.Image
 	spriteIso *ebiten.Image
 	height    ui.BlockSize
+	colour    ui.BlockOperation
 	pointIso  *Point
 	point2D   *Point
+	animation *TileAnimation
 }
 
 type TileStack struct {
+	x             int
+	y             int
 	stack         []*Tile
+	removed       []*Tile
 	currentIndex  int
 	currentHeight int
 	maxHeight     int
 	isHovered     bool
+	remoteHover   *color.RGBA
 }
 
 type Board struct {
-	data              [][]*TileStack
-	objectToTileStack map[string]*TileStack
-	originIso         *Point
-	origin2D          *Point
-	space             *resolv.Space
-	cursor            *resolv.Object
-	loader            *resource.Loader
-	maxHeight         int
+	data               [][]*TileStack
+	objectToTileStack  map[string]*TileStack
+	objectToSliceIndex map[string]int
+	originIso          *Point
+	origin2D           *Point
+	originElevation    *Point
+	camera             *Point
+	space              *resolv.Space
+	spaceOffset        Point
+	collisions         []*resolv.Object
+	cursor             *resolv.Object
+	loader             *resource.Loader
+	width              int
+	height             int
+	depth              int
+	maxHeight          int
+	layerHeight        int
+	animationsEnabled  bool
+	hoverTint          float64
+	palette            *ui.Palette
+	overlays           ui.Overlay
+	notes              map[[2]int]string
+	measure            *Measure
+	selection          *Selection
+	stamp              *Prefab
+	preview            *stampPreview
+	tileset            Tileset
+	sprites            *BlockSprites
+	tick               int
+	edits              []Edit
+	editHandler        func(edit Edit)
+	shadow             *Validator
+	undoStack          [][]Edit
+	group              []Edit
+	grouping           bool
+	rules              []Rule
+	screenWidth        float64
+	screenHeight       float64
 }
 
 const (
-	TILE_WIDTH_ISO      = 32
-	TILE_HEIGHT_ISO     = 16
-	TILE_FULL_DEPTH_ISO = 17
-	TILE_HALF_DEPTH_ISO = 9
-	TILE_WIDTH_2D       = 24
-	TILE_HEIGHT_2D      = 18
-	TILE_FULL_DEPTH_2D  = 14
-	TILE_HALF_DEPTH_2D  = 7
+	LAYER_HIDDEN_ALPHA = 0.15
+	// MAX_BOARD_SIZE is the widest and longest a board can be, and MAX_BOARD_DEPTH the
+	// most full blocks its stacks can be built up to.
+	MAX_BOARD_SIZE  = 256
+	MAX_BOARD_DEPTH = 64
+	// SPACE_CELL_SIZE and SPACE_MARGIN lay out the collision space used for picking.
+	SPACE_CELL_SIZE = 8
+	SPACE_MARGIN    = 16
 )
 
 func coordTag(x int, y int) string {
@@ -65,16 +103,12 @@ func stackKey(tags []string) string {
 	return strings.Join(tags[:], ",")
 }
 
-func (ts *TileStack) addTile(blockSize ui.BlockSize, blockOperation ui.BlockOperation, loader *resource.Loader) {
+func (ts *TileStack) addTile(blockSize ui.BlockSize, blockOperation ui.BlockOperation, sprites *BlockSprites) {
 	currentBlock := ts.stack[ts.currentIndex]
-	newBlock := newBlockTile(blockSize, blockOperation, loader)
+	newBlock := newBlockTile(blockSize, blockOperation, sprites)
 
-	yIncrementIso := TILE_FULL_DEPTH_ISO
-	yIncrement2D := TILE_FULL_DEPTH_2D
-	if blockSize == ui.HALF {
-		yIncrementIso = TILE_HALF_DEPTH_ISO
-		yIncrement2D = TILE_HALF_DEPTH_2D
-	}
+	yIncrementIso := sprites.tileset.Iso.depth(blockSize)
+	yIncrement2D := sprites.tileset.TwoD.depth(blockSize)
 
 	newBlock.pointIso = &Point{X: currentBlock.pointIso.X, Y: currentBlock.pointIso.Y - float64(yIncrementIso)}
 	newBlock.point2D = &Point{X: currentBlock.point2D.X, Y: currentBlock.point2D.Y - float64(yIncrement2D)}
@@ -83,99 +117,327 @@ func (ts *TileStack) addTile(blockSize ui.BlockSize, blockOperation ui.BlockOper
 	ts.currentIndex += 1
 }
 
-func (ts *TileStack) deleteTopTile() {
+func (ts *TileStack) deleteTopTile() *Tile {
 	if ts.currentIndex < 1 {
-		return
+		return nil
 	}
-	ts.currentHeight -= ts.stack[ts.currentIndex].height.GetHeight()
+	removed := ts.stack[ts.currentIndex]
+	ts.currentHeight -= removed.height.GetHeight()
 	ts.stack = ts.stack[:len(ts.stack)-1]
 	ts.currentIndex -= 1
+	return removed
+}
 
+// renderGround draws the ground tile, which is drawn for every stack before any blocks
+// so that overlays can go between them.
+func (ts *TileStack) renderGround(screen *ebiten.Image, renderer ui.Renderer, camera *Point, hoverTint float64) {
+	ground := ts.stack[0]
+	sprite, point := ground.sprite2D, ground.point2D
+	if renderer == ui.ISOMETRIC {
+		sprite, point = ground.spriteIso, ground.pointIso
+	}
+	ts.drawTile(screen, ground, sprite, point, camera, color.RGBA{}, hoverTint, false)
 }
 
-func (ts *TileStack) render2D(screen *ebiten.Image) {
-	for _, tile := range ts.stack {
-		if tile != nil {
-			drawOpts := &ebiten.DrawImageOptions{}
-			drawOpts.GeoM.Translate(tile.point2D.X, tile.point2D.Y)
-			if ts.isHovered {
-				drawOpts.ColorM.RotateHue(1.25)
-			}
-			screen.DrawImage(tile.sprite2D, drawOpts)
-		}
+// drawTile draws one of the stack's tiles in colour, highlighted if the stack is hovered.
+func (ts *TileStack) drawTile(screen *ebiten.Image, tile *Tile, sprite *ebiten.Image, point *Point, camera *Point, colour color.RGBA, hoverTint float64, hidden bool) {
+	drawOpts := tileDrawOptions(tile, sprite, point, camera, colour)
+	if ts.isHovered {
+		drawOpts.ColorM.RotateHue(HOVER_HUE_ROTATION * hoverTint)
+	}
+	tintRemoteHover(drawOpts, ts.remoteHover)
+	if hidden {
+		drawOpts.ColorScale.ScaleAlpha(LAYER_HIDDEN_ALPHA)
+	}
+	screen.DrawImage(sprite, drawOpts)
+}
+
+// blockColour is the colour a block is drawn in, which is heat for every block in the
+// stack when the heatmap is on.
+func blockColour(tile *Tile, palette *ui.Palette, heat *color.RGBA) color.RGBA {
+	if heat != nil && tile.height != ui.FLAT {
+		return *heat
+	}
+	return palette.Colour(tile.colour)
+}
+
+func (ts *TileStack) render2D(screen *ebiten.Image, camera *Point, layerHeight int, hoverTint float64, palette *ui.Palette, heat *color.RGBA) {
+	height := 0
+	for _, tile := range ts.stack[1:] {
+		height += tile.height.GetHeight()
+		ts.drawTile(screen, tile, tile.sprite2D, tile.point2D, camera, blockColour(tile, palette, heat), hoverTint, height > layerHeight)
+	}
+	for _, tile := range ts.removed {
+		screen.DrawImage(tile.sprite2D, tileDrawOptions(tile, tile.sprite2D, tile.point2D, camera, blockColour(tile, palette, heat)))
 	}
 }
 
 func (b *Board) Render2D(screen *ebiten.Image) {
 	for _, row := range b.data {
 		for _, tileStack := range row {
-			tileStack.render2D(screen)
+			tileStack.renderGround(screen, ui.TWO_DIMENSIONAL, b.camera, b.hoverTint)
+		}
+	}
+	b.renderGrid(screen, ui.TWO_DIMENSIONAL)
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			tileStack.render2D(screen, b.camera, b.layerHeight, b.hoverTint, b.palette, b.heat(tileStack))
 		}
 	}
+	b.renderLabels(screen, ui.TWO_DIMENSIONAL)
+	b.renderAnnotations(screen, func(tileStack *TileStack) (float64, float64, bool) {
+		x, y := b.topCentre(ui.TWO_DIMENSIONAL, tileStack)
+		return x, y, true
+	})
+	b.renderPrefabTool(screen, ui.TWO_DIMENSIONAL)
 }
 
-func (ts *TileStack) renderIso(screen *ebiten.Image) {
-	for _, tile := range ts.stack {
-		if tile != nil {
-			drawOpts := &ebiten.DrawImageOptions{}
-			drawOpts.GeoM.Translate(tile.pointIso.X, tile.pointIso.Y)
-			if ts.isHovered {
-				drawOpts.ColorM.RotateHue(1.25)
-			}
-			screen.DrawImage(tile.spriteIso, drawOpts)
-		}
+func (ts *TileStack) renderIso(screen *ebiten.Image, camera *Point, layerHeight int, hoverTint float64, palette *ui.Palette, heat *color.RGBA) {
+	height := 0
+	for _, tile := range ts.stack[1:] {
+		height += tile.height.GetHeight()
+		ts.drawTile(screen, tile, tile.spriteIso, tile.pointIso, camera, blockColour(tile, palette, heat), hoverTint, height > layerHeight)
+	}
+	for _, tile := range ts.removed {
+		screen.DrawImage(tile.spriteIso, tileDrawOptions(tile, tile.spriteIso, tile.pointIso, camera, blockColour(tile, palette, heat)))
 	}
 }
 
 func (b *Board) RenderIso(screen *ebiten.Image) {
 	for j := 0; j < len(b.data); j++ {
 		for i := len(b.data[j]) - 1; i >= 0; i-- {
-			b.data[j][i].renderIso(screen)
+			b.data[j][i].renderGround(screen, ui.ISOMETRIC, b.camera, b.hoverTint)
+		}
+	}
+	b.renderGrid(screen, ui.ISOMETRIC)
+	for j := 0; j < len(b.data); j++ {
+		for i := len(b.data[j]) - 1; i >= 0; i-- {
+			b.data[j][i].renderIso(screen, b.camera, b.layerHeight, b.hoverTint, b.palette, b.heat(b.data[j][i]))
+		}
+	}
+	b.renderLabels(screen, ui.ISOMETRIC)
+	b.renderAnnotations(screen, func(tileStack *TileStack) (float64, float64, bool) {
+		x, y := b.topCentre(ui.ISOMETRIC, tileStack)
+		return x, y, true
+	})
+	b.renderPrefabTool(screen, ui.ISOMETRIC)
+}
+
+func (b *Board) Dimensions() (int, int) {
+	return len(b.data[0]), len(b.data)
+}
+
+func (b *Board) MaxHeight() int {
+	return b.maxHeight
+}
+
+func (b *Board) TopBlock(x int, y int) (ui.BlockOperation, int) {
+	tileStack := b.data[y][x]
+	return tileStack.stack[tileStack.currentIndex].colour, tileStack.currentHeight
+}
+
+func (b *Board) Heights() [][]int {
+	heights := make([][]int, len(b.data))
+	for y, row := range b.data {
+		heights[y] = make([]int, len(row))
+		for x, tileStack := range row {
+			heights[y][x] = tileStack.currentHeight
+		}
+	}
+	return heights
+}
+
+func (b *Board) HoveredStack() (int, int, bool) {
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			if tileStack.isHovered {
+				return tileStack.x, tileStack.y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (b *Board) canPlaceBlock(tileStack *TileStack, state *ui.State) (bool, string) {
+	return b.checkRules(tileStack, Block{Size: state.BlockSize, Colour: *state.BlockOperation}, b.layerHeight)
+}
+
+func (b *Board) canDeleteBlock(tileStack *TileStack) bool {
+	return tileStack.currentIndex > 0 && tileStack.currentHeight <= b.layerHeight
+}
+
+func (b *Board) updateLayer(state *ui.State, handler *input.Handler) {
+	if state.KeyboardCaptured() {
+		// Keys belong to the console or palette editor while they are open.
+	} else if handler.ActionIsJustPressed(ui.ActionLayerUp) {
+		state.LayerHeight++
+	} else if handler.ActionIsJustPressed(ui.ActionLayerDown) {
+		state.LayerHeight--
+	}
+	if state.LayerHeight > b.maxHeight {
+		state.LayerHeight = b.maxHeight
+	}
+	if state.LayerHeight < 0 {
+		state.LayerHeight = 0
+	}
+	b.layerHeight = state.LayerHeight
+}
+
+// placeSound picks the half or full block sound for blockSize, and a pitch that falls as
+// blocks get taller so that every size sounds different.
+func placeSound(blockSize ui.BlockSize) (resource.AudioID, float64) {
+	id, recorded := assets.AudioPlaceHalf, ui.HALF
+	if blockSize >= ui.FULL {
+		id, recorded = assets.AudioPlaceFull, ui.FULL
+	}
+	return id, math.Sqrt(float64(recorded.GetHeight()) / float64(blockSize.GetHeight()))
+}
+
+// buildSpace makes a collision space covering the tiles and the elevation columns for the
+// current max height. The space starts at 0, 0 while boards can reach past the screen in
+// any direction, so every collision object and the cursor are moved by spaceOffset.
+func (b *Board) buildSpace() {
+	sliceLength := b.width
+	if b.height > sliceLength {
+		sliceLength = b.height
+	}
+	elevation := b.elevationCollisions(sliceLength, b.maxHeight)
+	objects := append(append([]*resolv.Object{}, b.collisions...), elevation...)
+
+	// Objects are already moved by the previous offset, so this finds the change to it.
+	left, top, right, bottom := objects[0].X, objects[0].Y, objects[0].X+objects[0].W, objects[0].Y+objects[0].H
+	for _, object := range objects[1:] {
+		left, top = math.Min(left, object.X), math.Min(top, object.Y)
+		right, bottom = math.Max(right, object.X+object.W), math.Max(bottom, object.Y+object.H)
+	}
+	dx, dy := SPACE_MARGIN-left, SPACE_MARGIN-top
+	b.spaceOffset.X += dx
+	b.spaceOffset.Y += dy
+
+	space := resolv.NewSpace(int(right-left)+2*SPACE_MARGIN, int(bottom-top)+2*SPACE_MARGIN, SPACE_CELL_SIZE, SPACE_CELL_SIZE)
+	for _, object := range objects {
+		object.X += dx
+		object.Y += dy
+		space.Add(object)
+	}
+	space.Add(b.cursor)
+	b.space = space
+	b.objectToSliceIndex = make(map[string]int)
+	for i, object := range elevation {
+		b.objectToSliceIndex[stackKey(object.Tags())] = i
+	}
+}
+
+// pick returns the collision object with tag under the cursor. The space's cells are
+// coarser than a pixel, so candidates are narrowed down to those the cursor is inside,
+// nearest first.
+func (b *Board) pick(tag string) *resolv.Object {
+	check := b.cursor.Check(0, 0, tag)
+	if check == nil {
+		return nil
+	}
+	for _, object := range check.Objects {
+		if b.cursor.X >= object.X && b.cursor.X < object.X+object.W && b.cursor.Y >= object.Y && b.cursor.Y < object.Y+object.H {
+			return object
 		}
 	}
+	return nil
 }
 
-func (b *Board) canPlaceBlock(tileStack *TileStack, state *ui.State) bool {
-	size := state.BlockSize.GetHeight()
-	return tileStack.currentHeight+size <= b.maxHeight && *state.BlockOperation != ui.SELECT
+func (b *Board) hoveredTileStack(state *ui.State) *TileStack {
+	switch state.Renderer {
+	case ui.ISOMETRIC:
+		if object := b.pick("ISO"); object != nil {
+			return b.objectToTileStack[stackKey(object.Tags())]
+		}
+	case ui.TWO_DIMENSIONAL:
+		if object := b.pick("2D"); object != nil {
+			return b.objectToTileStack[stackKey(object.Tags())]
+		}
+	case ui.ELEVATION:
+		return b.elevationTileStack(state)
+	}
+	return nil
 }
 
 func (b *Board) Update(state *ui.State, handler *input.Handler) {
+	b.tick++
+	if !state.KeyboardCaptured() {
+		b.updateCamera(handler)
+	}
+	b.clampElevationIndex(state)
+	b.updateLayer(state, handler)
+	b.updateAnimations(state.Settings.Animations)
+	b.hoverTint = float64(state.Settings.HoverTint) / 100
+	b.overlays = state.Overlays
+	if state.Tool != ui.TOOL_MEASURE {
+		b.measure = nil
+	}
+	if state.Tool != ui.TOOL_PREFAB {
+		b.selection = nil
+	}
+	b.preview = nil
 	x, y := ebiten.CursorPosition()
-	b.cursor.X = float64(x)
-	b.cursor.Y = float64(y)
+	b.cursor.X = float64(x) + b.camera.X + b.spaceOffset.X
+	b.cursor.Y = float64(y) + b.camera.Y + b.spaceOffset.Y
 	for _, row := range b.data {
 		for _, tileStack := range row {
 			tileStack.isHovered = false
 		}
 	}
+	if state.Playback {
+		return
+	}
+	if !state.KeyboardCaptured() && handler.ActionIsJustPressed(ui.ActionUndo) {
+		if err := b.Undo(); err != nil {
+			state.Warn(err.Error())
+		}
+	}
+	if !state.KeyboardCaptured() && state.Tool == ui.TOOL_PREFAB {
+		if handler.ActionIsJustPressed(ui.ActionRotatePrefab) {
+			state.PrefabRotation = (state.PrefabRotation + 1) % 4
+		}
+		if handler.ActionIsJustPressed(ui.ActionMirrorPrefab) {
+			state.PrefabMirror = !state.PrefabMirror
+		}
+	}
+	if state.CursorOverUI {
+		return
+	}
 
-	if check := b.cursor.Check(0, 0, "ISO"); check != nil && state.Renderer == ui.ISOMETRIC {
-		if tileStack := b.objectToTileStack[stackKey(check.Objects[0].Tags())]; tileStack != nil {
-			tileStack.isHovered = true
-			if handler.ActionIsJustPressed(ui.ActionSelect) {
-				if b.canPlaceBlock(tileStack, state) {
-					tileStack.addTile(state.BlockSize, *state.BlockOperation, b.loader)
-				} else if *state.BlockOperation != ui.SELECT {
-					state.AnimateAlert = true
-				}
-			} else if handler.ActionIsJustPressed(ui.ActionDelete) {
-				tileStack.deleteTopTile()
+	tileStack := b.hoveredTileStack(state)
+	if tileStack == nil {
+		return
+	}
+	tileStack.isHovered = true
+	switch state.Tool {
+	case ui.TOOL_FILL:
+		if handler.ActionIsJustPressed(ui.ActionSelect) && *state.BlockOperation != ui.SELECT {
+			result := b.floodFill(tileStack, state.BlockSize, *state.BlockOperation)
+			if result.Changed > 0 {
+				state.PlaySoundPitched(placeSound(state.BlockSize))
+				state.Info(result.String())
+			} else {
+				state.Warn(result.String())
 			}
 		}
-	}
-	if check := b.cursor.Check(0, 0, "2D"); check != nil && state.Renderer == ui.TWO_DIMENSIONAL {
-		if tileStack := b.objectToTileStack[stackKey(check.Objects[0].Tags())]; tileStack != nil {
-			tileStack.isHovered = true
-			if handler.ActionIsJustPressed(ui.ActionSelect) {
-				if b.canPlaceBlock(tileStack, state) {
-					tileStack.addTile(state.BlockSize, *state.BlockOperation, b.loader)
-				} else if *state.BlockOperation != ui.SELECT {
-					state.AnimateAlert = true
-				}
-			} else if handler.ActionIsJustPressed(ui.ActionDelete) {
-				tileStack.deleteTopTile()
+	case ui.TOOL_MEASURE:
+		b.updateMeasure(tileStack, handler)
+	case ui.TOOL_NOTE:
+		b.updateNote(tileStack, state, handler)
+	case ui.TOOL_PREFAB:
+		b.updatePrefab(tileStack, state, handler)
+	default:
+		if handler.ActionIsJustPressed(ui.ActionSelect) {
+			if ok, reason := b.canPlaceBlock(tileStack, state); ok {
+				b.submit(b.newPlaceEdit(tileStack, state.BlockSize, *state.BlockOperation))
+				state.PlaySoundPitched(placeSound(state.BlockSize))
+			} else if reason != "" {
+				state.Warn(reason)
 			}
+		} else if handler.ActionIsJustPressed(ui.ActionDelete) && b.canDeleteBlock(tileStack) {
+			b.submit(b.newDeleteEdit(tileStack))
+			state.PlaySound(assets.AudioDelete)
 		}
 	}
 }
@@ -188,39 +450,16 @@ func newGroundTile(loader *resource.Loader) *Tile {
 	}
 }
 
-func newBlockTile(blockSize ui.BlockSize, blockOperation ui.BlockOperation, loader *resource.Loader) *Tile {
-	var sprite2D *ebiten.Image
-	var spriteIso *ebiten.Image
-	switch true {
-	case (blockOperation == ui.PLACE_BLUE && blockSize == ui.HALF):
-		sprite2D = loader.LoadImage(assets.ImgBlueHalfCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgBlueHalfCubeIso).Data
-
-	case (blockOperation == ui.PLACE_RED && blockSize == ui.HALF):
-		sprite2D = loader.LoadImage(assets.ImgRedHalfCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgRedHalfCubeIso).Data
-
-	case (blockOperation == ui.PLACE_YELLOW && blockSize == ui.HALF):
-		sprite2D = loader.LoadImage(assets.ImgYellowHalfCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgYellowHalfCubeIso).Data
-
-	case (blockOperation == ui.PLACE_BLUE && blockSize == ui.FULL):
-		sprite2D = loader.LoadImage(assets.ImgBlueCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgBlueCubeIso).Data
-
-	case (blockOperation == ui.PLACE_RED && blockSize == ui.FULL):
-		sprite2D = loader.LoadImage(assets.ImgRedCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgRedCubeIso).Data
-
-	case (blockOperation == ui.PLACE_YELLOW && blockSize == ui.FULL):
-		sprite2D = loader.LoadImage(assets.ImgYellowCube2D).Data
-		spriteIso = loader.LoadImage(assets.ImgYellowCubeIso).Data
-	}
+// newBlockTile uses the greyscale sprites for blockSize, which are tinted with the
+// palette colour when drawn.
+func newBlockTile(blockSize ui.BlockSize, blockOperation ui.BlockOperation, sprites *BlockSprites) *Tile {
+	sprite2D, spriteIso := sprites.get(blockSize)
 
 	return &Tile{
 		sprite2D:  sprite2D,
 		spriteIso: spriteIso,
 		height:    blockSize,
+		colour:    blockOperation,
 	}
 }
 
@@ -229,6 +468,8 @@ func newTileStack(x int, y int, maxHeight int, loader *resource.Loader) *TileSta
 	stack[0] = newGroundTile(loader)
 
 	return &TileStack{
+		x:             x,
+		y:             y,
 		stack:         stack,
 		currentIndex:  0,
 		currentHeight: stack[0].height.GetHeight(),
@@ -236,79 +477,126 @@ func newTileStack(x int, y int, maxHeight int, loader *resource.Loader) *TileSta
 	}
 }
 
-func new2DCollision(x float64, y float64, tag string) *resolv.Object {
-	return resolv.NewObject(x, y, TILE_WIDTH_2D, TILE_HEIGHT_2D, "2D", tag)
+func new2DCollision(x float64, y float64, geometry TileGeometry, tag string) *resolv.Object {
+	return resolv.NewObject(x, y, float64(geometry.Width), float64(geometry.Height), "2D", tag)
 }
 
-func newIsoCollision(x float64, y float64, tag string) *resolv.Object {
-	object := resolv.NewObject(x, y, TILE_WIDTH_2D, TILE_HEIGHT_2D, "ISO", tag)
+func newIsoCollision(x float64, y float64, geometry TileGeometry, tag string) *resolv.Object {
+	w, h := float64(geometry.Width), float64(geometry.Height)
+	object := resolv.NewObject(x, y, w, h, "ISO", tag)
 	object.SetShape(resolv.NewConvexPolygon(
 		x, y,
-		TILE_WIDTH_ISO/2, 0,
-		TILE_WIDTH_ISO, TILE_HEIGHT_ISO/2,
-		TILE_WIDTH_ISO/2, TILE_HEIGHT_ISO,
-		0, TILE_HEIGHT_ISO/2,
+		w/2, 0,
+		w, h/2,
+		w/2, h,
+		0, h/2,
 	))
 	return object
 }
 
 func NewBoard(w int, h int, d int, cursor *resolv.Object, loader *resource.Loader) *Board {
-	data := make([][]*TileStack, w)
+	board := &Board{
+		rules:        append([]Rule{}, DEFAULT_RULES...),
+		palette:      ui.DefaultPalette(),
+		notes:        make(map[[2]int]string),
+		camera:       &Point{},
+		cursor:       cursor,
+		loader:       loader,
+		screenWidth:  config.ScreenWidth,
+		screenHeight: config.ScreenHeight,
+	}
+	// The manifest comes through the loader, so that it is the one the sprites were loaded
+	// with. A broken manifest is reported at startup and gives the default tileset.
+	board.tileset, _ = LoadTileset(func(path string) ([]byte, error) {
+		return loader.LoadRaw(assets.RawTileset).Data, nil
+	})
+	board.sprites = newBlockSprites(loader, board.tileset)
+	board.Reset(w, h, d)
+	return board
+}
+
+// CheckBoardSize returns an error unless a w x h board with stacks of up to d full
+// blocks is within MAX_BOARD_SIZE and MAX_BOARD_DEPTH.
+func CheckBoardSize(w int, h int, d int) error {
+	if w < 1 || h < 1 || w > MAX_BOARD_SIZE || h > MAX_BOARD_SIZE {
+		return fmt.Errorf("a %dx%d board is not between 1x1 and %dx%d", w, h, MAX_BOARD_SIZE, MAX_BOARD_SIZE)
+	}
+	if d < 1 || d > MAX_BOARD_DEPTH {
+		return fmt.Errorf("a depth of %d is not between 1 and %d blocks", d, MAX_BOARD_DEPTH)
+	}
+	return nil
+}
+
+// Reset rebuilds the board as an empty w x h grid of stacks holding up to d full blocks.
+func (b *Board) Reset(w int, h int, d int) {
+	data := make([][]*TileStack, h)
 	objectToTileStack := make(map[string]*TileStack)
 
 	originIso := &Point{
-		X: float64(config.ScreenWidth)/2 - float64(w*TILE_WIDTH_ISO)/2,
-		Y: float64(config.ScreenHeight)/1.25 - float64(h*TILE_HEIGHT_ISO)/2,
+		X: float64(config.ScreenWidth)/2 - float64(w*b.tileset.Iso.Width)/2,
+		Y: float64(config.ScreenHeight)/1.25 - float64(h*b.tileset.Iso.Height)/2,
 	}
 	origin2D := &Point{
-		X: float64(config.ScreenWidth)/2 - float64(w*TILE_WIDTH_2D)/2,
-		Y: float64(config.ScreenHeight)/1.75 - float64(h*TILE_HEIGHT_2D)/2,
+		X: float64(config.ScreenWidth)/2 - float64(w*b.tileset.TwoD.Width)/2,
+		Y: float64(config.ScreenHeight)/1.75 - float64(h*b.tileset.TwoD.Height)/2,
 	}
-	space := resolv.NewSpace(config.ScreenWidth, config.ScreenHeight, 1, 1)
+	collisions := []*resolv.Object{}
 
 	for y := range data {
-		data[y] = make([]*TileStack, h)
+		data[y] = make([]*TileStack, w)
 		for x := range data[y] {
-			tileStack := newTileStack(x, y, d, loader)
+			tileStack := newTileStack(x, y, d, b.loader)
 
-			xIso, yIso := calculateIsoCoord(originIso, x, y)
+			xIso, yIso := calculateIsoCoord(originIso, b.tileset.Iso, x, y)
 			tileStack.stack[0].pointIso = &Point{X: xIso, Y: yIso}
-			collisionIso := newIsoCollision(tileStack.stack[0].pointIso.X, tileStack.stack[0].pointIso.Y, coordTag(x, y))
-			space.Add(collisionIso)
+			collisionIso := newIsoCollision(tileStack.stack[0].pointIso.X, tileStack.stack[0].pointIso.Y, b.tileset.Iso, coordTag(x, y))
+			collisions = append(collisions, collisionIso)
 			objectToTileStack[stackKey(collisionIso.Tags())] = tileStack
 
-			x2D, y2D := calculate2DCoord(origin2D, x, y)
+			x2D, y2D := calculate2DCoord(origin2D, b.tileset.TwoD, x, y)
 			tileStack.stack[0].point2D = &Point{X: x2D, Y: y2D}
-			collision2D := new2DCollision(tileStack.stack[0].point2D.X, tileStack.stack[0].point2D.Y, coordTag(x, y))
-			space.Add(collision2D)
+			collision2D := new2DCollision(tileStack.stack[0].point2D.X, tileStack.stack[0].point2D.Y, b.tileset.TwoD, coordTag(x, y))
+			collisions = append(collisions, collision2D)
 			objectToTileStack[stackKey(collision2D.Tags())] = tileStack
 
 			data[y][x] = tileStack
 		}
 	}
 
-	space.Add(cursor)
-
-	return &Board{
-		data:              data,
-		objectToTileStack: objectToTileStack,
-		originIso:         originIso,
-		origin2D:          origin2D,
-		space:             space,
-		cursor:            cursor,
-		loader:            loader,
-		maxHeight:         d * 2,
+	sliceLength := w
+	if h > sliceLength {
+		sliceLength = h
 	}
+
+	b.data = data
+	b.objectToTileStack = objectToTileStack
+	b.originIso = originIso
+	b.origin2D = origin2D
+	b.originElevation = newElevationOrigin(sliceLength)
+	b.collisions = collisions
+	b.spaceOffset = Point{}
+	b.width = w
+	b.height = h
+	b.depth = d
+	b.maxHeight = d * ui.FULL.GetHeight()
+	b.buildSpace()
+	b.layerHeight = b.maxHeight
+	b.tick = 0
+	b.edits = nil
+	b.undoStack = nil
+	b.measure = nil
+	b.selection = nil
+	b.dropNotesOutside(w, h)
 }
 
-func calculateIsoCoord(originIso *Point, x int, y int) (float64, float64) {
-	xIso := originIso.X + float64((x*(TILE_WIDTH_ISO/2))+(y*(TILE_WIDTH_ISO/2)))
-	yIso := originIso.Y + float64((y*(TILE_HEIGHT_ISO/2))-(x*(TILE_HEIGHT_ISO/2)))
+func calculateIsoCoord(originIso *Point, geometry TileGeometry, x int, y int) (float64, float64) {
+	xIso := originIso.X + float64((x*(geometry.Width/2))+(y*(geometry.Width/2)))
+	yIso := originIso.Y + float64((y*(geometry.Height/2))-(x*(geometry.Height/2)))
 	return xIso, yIso
 }
 
-func calculate2DCoord(origin2D *Point, x int, y int) (float64, float64) {
-	x2D := origin2D.X + float64(x*TILE_WIDTH_2D)
-	y2D := origin2D.Y + float64(y*TILE_HEIGHT_2D)
+func calculate2DCoord(origin2D *Point, geometry TileGeometry, x int, y int) (float64, float64) {
+	x2D := origin2D.X + float64(x*geometry.Width)
+	y2D := origin2D.Y + float64(y*geometry.Height)
 	return x2D, y2D
 }
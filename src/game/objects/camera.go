@@ -0,0 +1,84 @@
+package objects
+
+import (
+	input "github.com/quasilyte/ebitengine-input"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const CAMERA_PAN_SPEED = 4
+
+func (b *Board) updateCamera(handler *input.Handler) {
+	if handler.ActionIsPressed(ui.ActionPanUp) {
+		b.camera.Y -= CAMERA_PAN_SPEED
+	}
+	if handler.ActionIsPressed(ui.ActionPanDown) {
+		b.camera.Y += CAMERA_PAN_SPEED
+	}
+	if handler.ActionIsPressed(ui.ActionPanLeft) {
+		b.camera.X -= CAMERA_PAN_SPEED
+	}
+	if handler.ActionIsPressed(ui.ActionPanRight) {
+		b.camera.X += CAMERA_PAN_SPEED
+	}
+}
+
+// cellToWorld maps a fractional cell position, where (x, y) is the top left
+// corner of cell (x, y) when viewed top-down, to a position in board space.
+func (b *Board) cellToWorld(renderer ui.Renderer, x float64, y float64) (float64, float64) {
+	iso, twoD := b.tileset.Iso, b.tileset.TwoD
+	if renderer == ui.ISOMETRIC {
+		w, h := float64(iso.Width), float64(iso.Height)
+		return b.originIso.X + (x+y)*w/2, b.originIso.Y + (y-x)*h/2 + h/2
+	}
+	return b.origin2D.X + x*float64(twoD.Width), b.origin2D.Y + y*float64(twoD.Height)
+}
+
+func (b *Board) worldToCell(renderer ui.Renderer, x float64, y float64) (float64, float64) {
+	iso, twoD := b.tileset.Iso, b.tileset.TwoD
+	if renderer == ui.ISOMETRIC {
+		w, h := float64(iso.Width), float64(iso.Height)
+		u := (x - b.originIso.X) / (w / 2)
+		v := (y - b.originIso.Y - h/2) / (h / 2)
+		return (u - v) / 2, (u + v) / 2
+	}
+	return (x - b.origin2D.X) / float64(twoD.Width), (y - b.origin2D.Y) / float64(twoD.Height)
+}
+
+func (b *Board) Viewport(state *ui.State) [4]ui.MinimapPoint {
+	if state.Renderer == ui.ELEVATION {
+		return b.elevationViewport(state)
+	}
+
+	corners := [4]Point{
+		{X: 0, Y: 0},
+		{X: b.screenWidth, Y: 0},
+		{X: b.screenWidth, Y: b.screenHeight},
+		{X: 0, Y: b.screenHeight},
+	}
+	var viewport [4]ui.MinimapPoint
+	for i, corner := range corners {
+		x, y := b.worldToCell(state.Renderer, corner.X+b.camera.X, corner.Y+b.camera.Y)
+		viewport[i] = ui.MinimapPoint{X: x, Y: y}
+	}
+	return viewport
+}
+
+func (b *Board) JumpTo(state *ui.State, x float64, y float64) {
+	if state.Renderer == ui.ELEVATION {
+		b.elevationJumpTo(state, x, y)
+		return
+	}
+
+	worldX, worldY := b.cellToWorld(state.Renderer, x, y)
+	b.camera.X = worldX - b.screenWidth/2
+	b.camera.Y = worldY - b.screenHeight/2
+}
+
+// SetScreenSize keeps the view centred on the same point when the logical screen
+// changes size. Board positions are laid out for the default config screen size.
+func (b *Board) SetScreenSize(w int, h int) {
+	b.camera.X -= (float64(w) - b.screenWidth) / 2
+	b.camera.Y -= (float64(h) - b.screenHeight) / 2
+	b.screenWidth = float64(w)
+	b.screenHeight = float64(h)
+}
@@ -0,0 +1,81 @@
+package objects
+
+import "github.com/timothy-ch-cheung/go-game-block-placement/ui"
+
+type EditOperation int
+
+const (
+	PLACE EditOperation = iota
+	DELETE
+)
+
+// Edit is a single change to one stack. Deletes also carry the size and colour
+// of the block they removed so that they can be reversed.
+type Edit struct {
+	Tick      int
+	X         int
+	Y         int
+	Operation EditOperation
+	Size      ui.BlockSize
+	Colour    ui.BlockOperation
+}
+
+func (b *Board) newPlaceEdit(tileStack *TileStack, blockSize ui.BlockSize, blockOperation ui.BlockOperation) Edit {
+	return Edit{
+		Tick:      b.tick,
+		X:         tileStack.x,
+		Y:         tileStack.y,
+		Operation: PLACE,
+		Size:      blockSize,
+		Colour:    blockOperation,
+	}
+}
+
+func (b *Board) newDeleteEdit(tileStack *TileStack) Edit {
+	top := tileStack.stack[tileStack.currentIndex]
+	return Edit{
+		Tick:      b.tick,
+		X:         tileStack.x,
+		Y:         tileStack.y,
+		Operation: DELETE,
+		Size:      top.height,
+		Colour:    top.colour,
+	}
+}
+
+func (b *Board) inBounds(x int, y int) bool {
+	return y >= 0 && y < len(b.data) && x >= 0 && x < len(b.data[y])
+}
+
+// Apply performs edit on the board and records it in the session history.
+func (b *Board) Apply(edit Edit) {
+	if !b.inBounds(edit.X, edit.Y) {
+		return
+	}
+	tileStack := b.data[edit.Y][edit.X]
+	switch edit.Operation {
+	case PLACE:
+		tileStack.addTile(edit.Size, edit.Colour, b.sprites)
+		if b.animationsEnabled {
+			tileStack.stack[tileStack.currentIndex].animation = newTileAnimation(DROP_IN)
+		}
+	case DELETE:
+		removed := tileStack.deleteTopTile()
+		if removed == nil {
+			return
+		}
+		if b.animationsEnabled {
+			removed.animation = newTileAnimation(SHRINK_OUT)
+			tileStack.removed = append(tileStack.removed, removed)
+		}
+	}
+	b.edits = append(b.edits, edit)
+}
+
+func (b *Board) Tick() int {
+	return b.tick
+}
+
+func (b *Board) Edits() []Edit {
+	return b.edits
+}
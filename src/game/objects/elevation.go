@@ -0,0 +1,202 @@
+package objects
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// The side view is drawn with rectangles rather than sprites, so it keeps its own sizes.
+const (
+	TILE_WIDTH_ELEVATION        = 24
+	TILE_FULL_DEPTH_ELEVATION   = 14
+	TILE_GROUND_DEPTH_ELEVATION = 4
+)
+
+// Hover highlights at full tint strength; the settings scale them down.
+const (
+	HOVER_HUE_ROTATION = 1.25
+	HOVER_LIGHTEN      = 0.35
+)
+
+var elevationOutline = color.RGBA{R: 9, G: 10, B: 20, A: 255} // #090a14
+
+func elevationDepth(blockSize ui.BlockSize) float64 {
+	if blockSize == ui.FLAT {
+		return TILE_GROUND_DEPTH_ELEVATION
+	}
+	return elevationHeight(blockSize.GetHeight())
+}
+
+// elevationHeight is the height in pixels of height quarter blocks.
+func elevationHeight(height int) float64 {
+	return float64(height) * TILE_FULL_DEPTH_ELEVATION / float64(ui.FULL)
+}
+
+func elevationTag(i int) string {
+	return fmt.Sprintf("%d", i)
+}
+
+func newElevationCollision(x float64, groundY float64, maxHeight int, tag string) *resolv.Object {
+	height := elevationHeight(maxHeight+int(ui.HALF)) + TILE_GROUND_DEPTH_ELEVATION
+	return resolv.NewObject(x, groundY-height+TILE_GROUND_DEPTH_ELEVATION, TILE_WIDTH_ELEVATION, height, "ELEVATION", tag)
+}
+
+// elevationCollisions makes the elevation picking columns, which are as tall as maxHeight,
+// in the collision space's coordinates.
+func (b *Board) elevationCollisions(sliceLength int, maxHeight int) []*resolv.Object {
+	collisions := make([]*resolv.Object, sliceLength)
+	for i := range collisions {
+		x := b.originElevation.X + float64(i*TILE_WIDTH_ELEVATION) + b.spaceOffset.X
+		collisions[i] = newElevationCollision(x, b.originElevation.Y+b.spaceOffset.Y, maxHeight, elevationTag(i))
+	}
+	return collisions
+}
+
+func newElevationOrigin(length int) *Point {
+	return &Point{
+		X: float64(config.ScreenWidth)/2 - float64(length*TILE_WIDTH_ELEVATION)/2,
+		Y: float64(config.ScreenHeight) / 1.4,
+	}
+}
+
+func (b *Board) elevationSlice(state *ui.State) []*TileStack {
+	if state.ElevationAxis == ui.COLUMN {
+		slice := make([]*TileStack, len(b.data))
+		for y := range b.data {
+			slice[y] = b.data[y][state.ElevationIndex]
+		}
+		return slice
+	}
+	return b.data[state.ElevationIndex]
+}
+
+func (b *Board) clampElevationIndex(state *ui.State) {
+	w, h := b.Dimensions()
+	limit := h
+	if state.ElevationAxis == ui.COLUMN {
+		limit = w
+	}
+	if state.ElevationIndex >= limit {
+		state.ElevationIndex = limit - 1
+	}
+	if state.ElevationIndex < 0 {
+		state.ElevationIndex = 0
+	}
+}
+
+func (ts *TileStack) renderElevation(screen *ebiten.Image, x float64, groundY float64, layerHeight int, hoverTint float64, palette *ui.Palette, heat *color.RGBA) {
+	hover := 0.0
+	if ts.isHovered {
+		hover = hoverTint
+	}
+	y := groundY + TILE_GROUND_DEPTH_ELEVATION
+	height := 0
+	for _, tile := range ts.stack {
+		if tile != nil {
+			height += tile.height.GetHeight()
+			depth := elevationDepth(tile.height)
+			y -= depth
+			alpha := 1.0
+			if height > layerHeight {
+				alpha = LAYER_HIDDEN_ALPHA
+			}
+			drawElevationBar(screen, blockColour(tile, palette, heat), x, y, depth, alpha, hover, tile.animation)
+		}
+	}
+	for _, tile := range ts.removed {
+		drawElevationBar(screen, blockColour(tile, palette, heat), x, y-elevationDepth(tile.height), elevationDepth(tile.height), 1, 0, tile.animation)
+	}
+}
+
+func drawElevationBar(screen *ebiten.Image, colour color.RGBA, x float64, y float64, depth float64, alpha float64, hover float64, animation *TileAnimation) {
+	fill := lighten(colour, HOVER_LIGHTEN*hover)
+	if animation != nil {
+		offsetY, scale, animationAlpha := animation.transform()
+		y += offsetY + depth*(1-scale)
+		depth *= scale
+		alpha *= animationAlpha
+	}
+	fill = fade(fill, alpha)
+	outline := fade(elevationOutline, alpha)
+	vector.DrawFilledRect(screen, float32(x), float32(y), TILE_WIDTH_ELEVATION, float32(depth), fill, false)
+	vector.StrokeRect(screen, float32(x)+0.5, float32(y)+0.5, TILE_WIDTH_ELEVATION-1, float32(depth)-1, 1, outline, false)
+}
+
+func (b *Board) RenderElevation(screen *ebiten.Image, state *ui.State) {
+	b.clampElevationIndex(state)
+	slice := b.elevationSlice(state)
+	b.renderElevationGrid(screen, len(slice))
+	for i, tileStack := range slice {
+		x := b.originElevation.X + float64(i*TILE_WIDTH_ELEVATION) - b.camera.X
+		tileStack.renderElevation(screen, x, b.originElevation.Y-b.camera.Y, b.layerHeight, b.hoverTint, b.palette, b.heat(tileStack))
+	}
+	b.renderElevationLabels(screen, slice)
+	positions := make(map[*TileStack]int, len(slice))
+	for i, tileStack := range slice {
+		positions[tileStack] = i
+	}
+	b.renderAnnotations(screen, func(tileStack *TileStack) (float64, float64, bool) {
+		i, ok := positions[tileStack]
+		x := b.originElevation.X + (float64(i)+0.5)*TILE_WIDTH_ELEVATION - b.camera.X
+		return x, b.originElevation.Y - b.camera.Y - elevationHeight(tileStack.currentHeight), ok
+	})
+}
+
+func (b *Board) elevationTileStack(state *ui.State) *TileStack {
+	object := b.pick("ELEVATION")
+	if object == nil {
+		return nil
+	}
+	i, ok := b.objectToSliceIndex[stackKey(object.Tags())]
+	slice := b.elevationSlice(state)
+	if !ok || i >= len(slice) {
+		return nil
+	}
+	return slice[i]
+}
+
+func (b *Board) elevationViewport(state *ui.State) [4]ui.MinimapPoint {
+	start := (b.camera.X - b.originElevation.X) / TILE_WIDTH_ELEVATION
+	end := (b.camera.X + b.screenWidth - b.originElevation.X) / TILE_WIDTH_ELEVATION
+	index := float64(state.ElevationIndex)
+	if state.ElevationAxis == ui.COLUMN {
+		return [4]ui.MinimapPoint{{X: index, Y: start}, {X: index + 1, Y: start}, {X: index + 1, Y: end}, {X: index, Y: end}}
+	}
+	return [4]ui.MinimapPoint{{X: start, Y: index}, {X: end, Y: index}, {X: end, Y: index + 1}, {X: start, Y: index + 1}}
+}
+
+func (b *Board) elevationJumpTo(state *ui.State, x float64, y float64) {
+	position := x
+	state.ElevationIndex = int(y)
+	if state.ElevationAxis == ui.COLUMN {
+		position = y
+		state.ElevationIndex = int(x)
+	}
+	b.clampElevationIndex(state)
+	b.camera.X = b.originElevation.X + position*TILE_WIDTH_ELEVATION - b.screenWidth/2
+}
+
+// fade scales all channels since vector fills take premultiplied colours.
+func fade(c color.RGBA, alpha float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * alpha),
+		G: uint8(float64(c.G) * alpha),
+		B: uint8(float64(c.B) * alpha),
+		A: uint8(float64(c.A) * alpha),
+	}
+}
+
+func lighten(c color.RGBA, amount float64) color.RGBA {
+	return color.RGBA{
+		R: c.R + uint8(float64(255-c.R)*amount),
+		G: c.G + uint8(float64(255-c.G)*amount),
+		B: c.B + uint8(float64(255-c.B)*amount),
+		A: c.A,
+	}
+}
@@ -0,0 +1,152 @@
+package objects
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// FillResult counts the stacks a flood fill or colour replacement changed, and those it
+// skipped because they would pass the max height or another rule denied them.
+type FillResult struct {
+	Changed int
+	TooTall int
+	Denied  int
+}
+
+func (result FillResult) String() string {
+	message := fmt.Sprintf("%d stacks changed, %d skipped at max height", result.Changed, result.TooTall)
+	if result.Denied > 0 {
+		message += fmt.Sprintf(", %d denied by rules", result.Denied)
+	}
+	return message
+}
+
+// floodFill places a block on tileStack and every stack connected to it orthogonally
+// whose top block has the same colour and whose height is the same, as one undo step.
+func (b *Board) floodFill(tileStack *TileStack, blockSize ui.BlockSize, blockOperation ui.BlockOperation) FillResult {
+	top := tileStack.stack[tileStack.currentIndex]
+	matches := func(other *TileStack) bool {
+		return other.currentHeight == tileStack.currentHeight && other.stack[other.currentIndex].colour == top.colour
+	}
+
+	// The region is found before placing anything, since placing changes what matches.
+	region := []*TileStack{tileStack}
+	seen := map[*TileStack]bool{tileStack: true}
+	for i := 0; i < len(region); i++ {
+		for _, offset := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			x, y := region[i].x+offset[0], region[i].y+offset[1]
+			if !b.inBounds(x, y) || seen[b.data[y][x]] || !matches(b.data[y][x]) {
+				continue
+			}
+			seen[b.data[y][x]] = true
+			region = append(region, b.data[y][x])
+		}
+	}
+
+	result := FillResult{}
+	b.BeginGroup()
+	for _, target := range region {
+		if target.currentHeight+blockSize.GetHeight() > b.maxHeight {
+			result.TooTall++
+		} else if ok, _ := b.checkRules(target, Block{Size: blockSize, Colour: blockOperation}, b.layerHeight); !ok {
+			result.Denied++
+		} else {
+			b.submit(b.newPlaceEdit(target, blockSize, blockOperation))
+			result.Changed++
+		}
+	}
+	b.EndGroup()
+	return result
+}
+
+// ReplaceColour recolours every block of colour from on the board to colour to, as one
+// undo step. Blocks keep their size if to comes in it and take its nearest size if not,
+// so stacks that would then pass the max height or break a rule are left as they are.
+func (b *Board) ReplaceColour(from ui.BlockOperation, to ui.BlockOperation) (FillResult, error) {
+	if !b.palette.Has(from) || !b.palette.Has(to) {
+		return FillResult{}, errors.New("unknown block colour")
+	}
+	if from == to {
+		return FillResult{}, errors.New("the colours are the same")
+	}
+
+	result := FillResult{}
+	b.BeginGroup()
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			blocks := tileStack.stack[1 : tileStack.currentIndex+1]
+			lowest, height := -1, 0
+			replaced := make([]Block, len(blocks))
+			for i, tile := range blocks {
+				replaced[i] = Block{Size: tile.height, Colour: tile.colour}
+				if tile.colour == from {
+					if lowest < 0 {
+						lowest = i
+					}
+					replaced[i].Colour = to
+					if !b.palette.HasSize(to, tile.height) {
+						replaced[i].Size = b.palette.NearestSize(to, tile.height)
+					}
+				}
+				height += replaced[i].Size.GetHeight()
+			}
+			if lowest < 0 {
+				continue
+			}
+			if height > b.maxHeight {
+				result.TooTall++
+				continue
+			}
+			if !b.checkBlocks(tileStack, replaced[:lowest], replaced[lowest:]) {
+				result.Denied++
+				continue
+			}
+			// Edits only add or remove the top block, so the stack is rebuilt from the
+			// lowest block that changes.
+			for i := len(blocks) - 1; i >= lowest; i-- {
+				b.submit(Edit{
+					Tick:      b.tick,
+					X:         tileStack.x,
+					Y:         tileStack.y,
+					Operation: DELETE,
+					Size:      blocks[i].height,
+					Colour:    blocks[i].colour,
+				})
+			}
+			for _, block := range replaced[lowest:] {
+				b.submit(b.newPlaceEdit(tileStack, block.Size, block.Colour))
+			}
+			result.Changed++
+		}
+	}
+	b.EndGroup()
+	return result, nil
+}
+
+// checkBlocks runs the rules over each of blocks in turn, as if they were placed on
+// tileStack with only the blocks in below under them.
+func (b *Board) checkBlocks(tileStack *TileStack, below []Block, blocks []Block) bool {
+	target := StackView{X: tileStack.x, Y: tileStack.y}
+	for _, block := range below {
+		target.Blocks = append(target.Blocks, block)
+		target.Height += block.Size.GetHeight()
+	}
+	neighbours := b.neighbours(tileStack)
+	for _, block := range blocks {
+		placement := &Placement{
+			Target:      target,
+			Neighbours:  neighbours,
+			Block:       block,
+			MaxHeight:   b.maxHeight,
+			LayerHeight: b.layerHeight,
+		}
+		if ok, _ := checkPlacement(b.rules, placement); !ok {
+			return false
+		}
+		target.Blocks = append(target.Blocks, block)
+		target.Height = placement.NewHeight()
+	}
+	return true
+}
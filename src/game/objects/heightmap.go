@@ -0,0 +1,99 @@
+package objects
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// decodePNG reads the header first, so that an image too big for a board is refused
+// before its pixels are decoded.
+func decodePNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	config, err := png.DecodeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkImportSize(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return png.Decode(f)
+}
+
+func checkImportSize(w int, h int) error {
+	if w > MAX_BOARD_SIZE || h > MAX_BOARD_SIZE {
+		return fmt.Errorf("image is %dx%d, which is bigger than the largest board of %dx%d", w, h, MAX_BOARD_SIZE, MAX_BOARD_SIZE)
+	}
+	return nil
+}
+
+// ImportHeightmap resizes the board to the heightmap, which may be at most MAX_BOARD_SIZE
+// on each side, and builds each stack up to the pixel's brightness in half block steps,
+// where white reaches the last whole half block under the max height. Block colours come from the nearest palette colour in colours, or are
+// the first palette colour when colours is nil.
+func (b *Board) ImportHeightmap(heightmap image.Image, colours image.Image) error {
+	bounds := heightmap.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < 1 || h < 1 {
+		return fmt.Errorf("heightmap is empty")
+	}
+	if err := checkImportSize(w, h); err != nil {
+		return err
+	}
+	if colours != nil && (colours.Bounds().Dx() != w || colours.Bounds().Dy() != h) {
+		return fmt.Errorf("colour image is %dx%d but the heightmap is %dx%d", colours.Bounds().Dx(), colours.Bounds().Dy(), w, h)
+	}
+
+	maxHeight := b.maxHeight
+	steps := maxHeight / ui.HALF.GetHeight()
+	b.Reset(w, h, b.depth)
+	b.SetMaxHeight(maxHeight)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			brightness := color.GrayModel.Convert(heightmap.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y
+			level := int(math.Round(float64(brightness)/255*float64(steps))) * ui.HALF.GetHeight()
+			colour := b.palette.Colours()[0].ID
+			if colours != nil {
+				pixel := color.RGBAModel.Convert(colours.At(colours.Bounds().Min.X+x, colours.Bounds().Min.Y+y)).(color.RGBA)
+				colour = b.nearestBlockColour(pixel)
+			}
+			column := make([]ui.BlockOperation, level)
+			for z := range column {
+				column[z] = colour
+			}
+			for _, edit := range quantiseColumn(x, y, column, b.palette, b.maxHeight) {
+				edit.Tick = b.tick
+				b.Apply(edit)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadHeightmap imports PNG files, with colourPath left empty for a single colour board.
+func (b *Board) LoadHeightmap(heightmapPath string, colourPath string) error {
+	heightmap, err := decodePNG(heightmapPath)
+	if err != nil {
+		return err
+	}
+	var colours image.Image
+	if colourPath != "" {
+		if colours, err = decodePNG(colourPath); err != nil {
+			return err
+		}
+	}
+	return b.ImportHeightmap(heightmap, colours)
+}
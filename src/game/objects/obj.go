@@ -0,0 +1,167 @@
+package objects
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// OBJ_VOXEL_HEIGHT is the height of a quarter block relative to a cell width of 1.
+const OBJ_VOXEL_HEIGHT = 1 / float64(ui.FULL)
+
+// objMaterial names the material for a palette colour, which must be a single word.
+func objMaterial(colour ui.PaletteColour) string {
+	if name := strings.Join(strings.Fields(colour.Name), "_"); name != "" {
+		return name
+	}
+	return fmt.Sprintf("colour%d", colour.ID)
+}
+
+type objQuad struct {
+	corners [4][3]int
+	normal  int
+}
+
+// meshQuads greedily merges the exposed faces of each colour into as few rectangles as
+// possible. Axis 0 is the board x, axis 1 is height and axis 2 is the board y.
+func meshQuads(grid *voxelGrid) map[ui.BlockOperation][]objQuad {
+	dims := [3]int{grid.width, grid.height, grid.length}
+	get := func(p [3]int) ui.BlockOperation {
+		return grid.get(p[0], p[2], p[1])
+	}
+
+	quads := map[ui.BlockOperation][]objQuad{}
+	for d := 0; d < 3; d++ {
+		u, v := (d+1)%3, (d+2)%3
+		mask := make([]int, dims[u]*dims[v])
+		var p, q [3]int
+		q[d] = 1
+		for p[d] = -1; p[d] < dims[d]; {
+			// A positive mask entry is a face pointing along +d, negative along -d.
+			n := 0
+			for p[v] = 0; p[v] < dims[v]; p[v]++ {
+				for p[u] = 0; p[u] < dims[u]; p[u]++ {
+					a := get(p)
+					b := get([3]int{p[0] + q[0], p[1] + q[1], p[2] + q[2]})
+					switch {
+					case a != ui.SELECT && b == ui.SELECT:
+						mask[n] = int(a)
+					case a == ui.SELECT && b != ui.SELECT:
+						mask[n] = -int(b)
+					default:
+						mask[n] = 0
+					}
+					n++
+				}
+			}
+			p[d]++
+
+			n = 0
+			for j := 0; j < dims[v]; j++ {
+				for i := 0; i < dims[u]; {
+					face := mask[n]
+					if face == 0 {
+						i++
+						n++
+						continue
+					}
+					width := 1
+					for i+width < dims[u] && mask[n+width] == face {
+						width++
+					}
+					height := 1
+				grow:
+					for j+height < dims[v] {
+						for k := 0; k < width; k++ {
+							if mask[n+k+height*dims[u]] != face {
+								break grow
+							}
+						}
+						height++
+					}
+
+					var origin, du, dv [3]int
+					origin[d] = p[d]
+					origin[u], origin[v] = i, j
+					du[u], dv[v] = width, height
+					corners := [4][3]int{origin, add(origin, du), add(add(origin, du), dv), add(origin, dv)}
+					colour, normal := ui.BlockOperation(face), d
+					if face < 0 {
+						colour, normal = ui.BlockOperation(-face), d+3
+						corners[1], corners[3] = corners[3], corners[1]
+					}
+					quads[colour] = append(quads[colour], objQuad{corners: corners, normal: normal})
+
+					for l := 0; l < height; l++ {
+						for k := 0; k < width; k++ {
+							mask[n+k+l*dims[u]] = 0
+						}
+					}
+					i += width
+					n += width
+				}
+			}
+		}
+	}
+	return quads
+}
+
+func add(a [3]int, b [3]int) [3]int {
+	return [3]int{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+// WriteOBJ writes the board as a Wavefront mesh referencing materials in mtlName.
+func (b *Board) WriteOBJ(objWriter io.Writer, mtlWriter io.Writer, mtlName string) error {
+	obj := bufio.NewWriter(objWriter)
+	mtl := bufio.NewWriter(mtlWriter)
+
+	fmt.Fprintf(obj, "mtllib %s\n", mtlName)
+	for _, normal := range []string{"1 0 0", "0 1 0", "0 0 1", "-1 0 0", "0 -1 0", "0 0 -1"} {
+		fmt.Fprintf(obj, "vn %s\n", normal)
+	}
+
+	vertices := 0
+	quads := meshQuads(b.voxels())
+	for _, colour := range b.palette.Colours() {
+		c := colour.Colour
+		fmt.Fprintf(mtl, "newmtl %s\nKd %.4f %.4f %.4f\n\n", objMaterial(colour), float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+		if len(quads[colour.ID]) == 0 {
+			continue
+		}
+		fmt.Fprintf(obj, "usemtl %s\n", objMaterial(colour))
+		for _, quad := range quads[colour.ID] {
+			for _, corner := range quad.corners {
+				fmt.Fprintf(obj, "v %d %g %d\n", corner[0], float64(corner[1])*OBJ_VOXEL_HEIGHT, corner[2])
+			}
+			normal := quad.normal + 1
+			fmt.Fprintf(obj, "f %d//%d %d//%d %d//%d %d//%d\n", vertices+1, normal, vertices+2, normal, vertices+3, normal, vertices+4, normal)
+			vertices += 4
+		}
+	}
+
+	if err := obj.Flush(); err != nil {
+		return err
+	}
+	return mtl.Flush()
+}
+
+// SaveOBJ writes path and a material library next to it with the .mtl extension.
+func (b *Board) SaveOBJ(path string) error {
+	mtlPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".mtl"
+	objFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer objFile.Close()
+	mtlFile, err := os.Create(mtlPath)
+	if err != nil {
+		return err
+	}
+	defer mtlFile.Close()
+	return b.WriteOBJ(objFile, mtlFile, filepath.Base(mtlPath))
+}
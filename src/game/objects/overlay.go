@@ -0,0 +1,154 @@
+package objects
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+	"golang.org/x/image/font"
+)
+
+// GRID_COLOUR is premultiplied, as vector strokes expect.
+var GRID_COLOUR = color.RGBA{R: 64, G: 64, B: 64, A: 64}
+
+var LABEL_COLOUR = color.RGBA{R: 235, G: 237, B: 233, A: 255} // #ebede9
+
+// HEATMAP_COLOURS run from an empty stack to one at the max height.
+var HEATMAP_COLOURS = []color.RGBA{
+	{R: 60, G: 94, B: 139, A: 255},  // #3c5e8b
+	{R: 117, G: 167, B: 67, A: 255}, // #75a743
+	{R: 222, G: 158, B: 65, A: 255}, // #de9e41
+	{R: 165, G: 48, B: 48, A: 255},  // #a53030
+}
+
+func (b *Board) showOverlay(overlay ui.Overlay) bool {
+	return b.overlays&overlay != 0
+}
+
+// heat is the heatmap colour of tileStack, or nil when the heatmap is off.
+func (b *Board) heat(tileStack *TileStack) *color.RGBA {
+	if !b.showOverlay(ui.OVERLAY_HEATMAP) {
+		return nil
+	}
+	colour := heatColour(tileStack.currentHeight, b.maxHeight)
+	return &colour
+}
+
+func heatColour(height int, maxHeight int) color.RGBA {
+	t := 0.0
+	if maxHeight > 0 {
+		t = float64(height) / float64(maxHeight)
+	}
+	if t > 1 {
+		t = 1
+	}
+	position := t * float64(len(HEATMAP_COLOURS)-1)
+	i := int(position)
+	if i >= len(HEATMAP_COLOURS)-1 {
+		return HEATMAP_COLOURS[len(HEATMAP_COLOURS)-1]
+	}
+	from, to, f := HEATMAP_COLOURS[i], HEATMAP_COLOURS[i+1], position-float64(i)
+	mix := func(a uint8, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*f)
+	}
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+}
+
+// renderGrid outlines every cell on the ground.
+func (b *Board) renderGrid(screen *ebiten.Image, renderer ui.Renderer) {
+	if !b.showOverlay(ui.OVERLAY_GRID) {
+		return
+	}
+	w, h := float64(b.width), float64(b.height)
+	for x := 0; x <= b.width; x++ {
+		b.cellLine(screen, renderer, float64(x), 0, float64(x), h, GRID_COLOUR)
+	}
+	for y := 0; y <= b.height; y++ {
+		b.cellLine(screen, renderer, 0, float64(y), w, float64(y), GRID_COLOUR)
+	}
+}
+
+// cellLine draws a line on the ground between two fractional cell positions.
+func (b *Board) cellLine(screen *ebiten.Image, renderer ui.Renderer, x0 float64, y0 float64, x1 float64, y1 float64, colour color.RGBA) {
+	x0, y0 = b.cellToWorld(renderer, x0, y0)
+	x1, y1 = b.cellToWorld(renderer, x1, y1)
+	vector.StrokeLine(screen, float32(x0-b.camera.X), float32(y0-b.camera.Y), float32(x1-b.camera.X), float32(y1-b.camera.Y), 1, colour, false)
+}
+
+// topCentre is the screen position of the middle of the top face of tileStack.
+func (b *Board) topCentre(renderer ui.Renderer, tileStack *TileStack) (float64, float64) {
+	geometry, point := b.tileset.TwoD, tileStack.stack[tileStack.currentIndex].point2D
+	if renderer == ui.ISOMETRIC {
+		geometry, point = b.tileset.Iso, tileStack.stack[tileStack.currentIndex].pointIso
+	}
+	return point.X + float64(geometry.Width)/2 - b.camera.X, point.Y + float64(geometry.Height)/2 - b.camera.Y
+}
+
+// renderLabels draws the height of each stack on its top face and the cell coordinates
+// along the top and left edges of the board, in the order coordTag gives them.
+func (b *Board) renderLabels(screen *ebiten.Image, renderer ui.Renderer) {
+	face := b.loader.LoadFont(assets.FontDefault).Face
+	if b.showOverlay(ui.OVERLAY_HEIGHTS) {
+		for _, row := range b.data {
+			for _, tileStack := range row {
+				if tileStack.currentHeight == 0 {
+					continue
+				}
+				x, y := b.topCentre(renderer, tileStack)
+				drawLabel(screen, ui.FormatHeight(tileStack.currentHeight), face, x, y)
+			}
+		}
+	}
+	if b.showOverlay(ui.OVERLAY_RULERS) {
+		for x := 0; x < b.width; x++ {
+			wx, wy := b.cellToWorld(renderer, float64(x)+0.5, -0.5)
+			drawLabel(screen, strconv.Itoa(x), face, wx-b.camera.X, wy-b.camera.Y)
+		}
+		for y := 0; y < b.height; y++ {
+			wx, wy := b.cellToWorld(renderer, -0.5, float64(y)+0.5)
+			drawLabel(screen, strconv.Itoa(y), face, wx-b.camera.X, wy-b.camera.Y)
+		}
+	}
+}
+
+// renderElevationGrid draws a line at every whole block of height across the slice.
+func (b *Board) renderElevationGrid(screen *ebiten.Image, length int) {
+	if !b.showOverlay(ui.OVERLAY_GRID) {
+		return
+	}
+	x0 := b.originElevation.X - b.camera.X
+	x1 := x0 + float64(length*TILE_WIDTH_ELEVATION)
+	for height := int(ui.FULL); height <= b.maxHeight; height += int(ui.FULL) {
+		y := b.originElevation.Y - b.camera.Y - elevationHeight(height)
+		vector.StrokeLine(screen, float32(x0), float32(y), float32(x1), float32(y), 1, GRID_COLOUR, false)
+	}
+}
+
+// renderElevationLabels puts heights above each stack and each stack's position in
+// the slice below the ground.
+func (b *Board) renderElevationLabels(screen *ebiten.Image, slice []*TileStack) {
+	face := b.loader.LoadFont(assets.FontDefault).Face
+	groundY := b.originElevation.Y - b.camera.Y
+	for i, tileStack := range slice {
+		x := b.originElevation.X + (float64(i)+0.5)*TILE_WIDTH_ELEVATION - b.camera.X
+		if b.showOverlay(ui.OVERLAY_HEIGHTS) && tileStack.currentHeight > 0 {
+			drawLabel(screen, ui.FormatHeight(tileStack.currentHeight), face, x, groundY-elevationHeight(tileStack.currentHeight)-8)
+		}
+		if b.showOverlay(ui.OVERLAY_RULERS) {
+			drawLabel(screen, strconv.Itoa(i), face, x, groundY+TILE_GROUND_DEPTH_ELEVATION+8)
+		}
+	}
+}
+
+// drawLabel centres label on x, y with a dark shadow so it reads over any block.
+func drawLabel(screen *ebiten.Image, label string, face font.Face, x float64, y float64) {
+	bounds := text.BoundString(face, label)
+	left := int(x) - bounds.Dx()/2 - bounds.Min.X
+	baseline := int(y) - bounds.Dy()/2 - bounds.Min.Y
+	text.Draw(screen, label, face, left+1, baseline+1, elevationOutline)
+	text.Draw(screen, label, face, left, baseline, LABEL_COLOUR)
+}
@@ -0,0 +1,8 @@
+package objects
+
+import "github.com/timothy-ch-cheung/go-game-block-placement/ui"
+
+// Palette is shared with the UI, which edits it in place.
+func (b *Board) Palette() *ui.Palette {
+	return b.palette
+}
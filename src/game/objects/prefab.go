@@ -0,0 +1,469 @@
+package objects
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	input "github.com/quasilyte/ebitengine-input"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const (
+	PREFAB_EXTENSION   = ".prefab"
+	MAX_PREFAB_SIZE    = 32
+	PREFAB_GHOST_ALPHA = 0.5
+)
+
+var SELECTION_COLOUR = color.RGBA{R: 232, G: 193, B: 112, A: 255} // #e8c170
+
+// Prefab is a rectangle of stacks saved for reuse. Stacks run row by row from the top
+// left and hold their blocks from the ground up, in colours from Palette.
+type Prefab struct {
+	Name    string
+	Width   int
+	Length  int
+	Palette []ui.PaletteColour
+	Stacks  [][]Block
+	file    string
+}
+
+// File is the name of the library file the prefab was loaded from or saved to, which
+// tells apart prefabs that share a name.
+func (prefab *Prefab) File() string {
+	return prefab.file
+}
+
+// Selection is the area dragged out with the prefab tool.
+type Selection struct {
+	startX int
+	startY int
+	endX   int
+	endY   int
+}
+
+// bounds gives the top left and bottom right cells of the selection.
+func (selection *Selection) bounds() (int, int, int, int) {
+	x0, x1 := selection.startX, selection.endX
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	y0, y1 := selection.startY, selection.endY
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	return x0, y0, x1, y1
+}
+
+type stampPreview struct {
+	prefab *Prefab
+	x      int
+	y      int
+}
+
+func (prefab *Prefab) stackAt(x int, y int) []Block {
+	return prefab.Stacks[y*prefab.Width+x]
+}
+
+// Transform mirrors the prefab left to right if mirror is set, then turns it rotation
+// quarter turns clockwise as seen from above.
+func (prefab *Prefab) Transform(rotation int, mirror bool) *Prefab {
+	result := *prefab
+	result.Stacks = make([][]Block, len(prefab.Stacks))
+	for y := 0; y < prefab.Length; y++ {
+		for x := 0; x < prefab.Width; x++ {
+			from := x
+			if mirror {
+				from = prefab.Width - 1 - x
+			}
+			result.Stacks[y*prefab.Width+x] = prefab.stackAt(from, y)
+		}
+	}
+	for turn := 0; turn < (rotation%4+4)%4; turn++ {
+		turned := make([][]Block, len(result.Stacks))
+		for y := 0; y < result.Length; y++ {
+			for x := 0; x < result.Width; x++ {
+				turned[x*result.Length+result.Length-1-y] = result.stackAt(x, y)
+			}
+		}
+		result.Stacks = turned
+		result.Width, result.Length = result.Length, result.Width
+	}
+	return &result
+}
+
+func (prefab *Prefab) tallest() int {
+	tallest := 0
+	for _, blocks := range prefab.Stacks {
+		height := 0
+		for _, block := range blocks {
+			height += block.Size.GetHeight()
+		}
+		if height > tallest {
+			tallest = height
+		}
+	}
+	return tallest
+}
+
+func (prefab *Prefab) validate() error {
+	if prefab.Name == "" {
+		return errors.New("prefab has no name")
+	}
+	if prefab.Width < 1 || prefab.Length < 1 || prefab.Width > MAX_PREFAB_SIZE || prefab.Length > MAX_PREFAB_SIZE {
+		return fmt.Errorf("prefab %s is %dx%d, which is not between 1x1 and %dx%d", prefab.Name, prefab.Width, prefab.Length, MAX_PREFAB_SIZE, MAX_PREFAB_SIZE)
+	}
+	if len(prefab.Stacks) != prefab.Width*prefab.Length {
+		return fmt.Errorf("prefab %s has %d stacks for a %dx%d area", prefab.Name, len(prefab.Stacks), prefab.Width, prefab.Length)
+	}
+	colours := ui.NewPalette(prefab.Palette)
+	for _, blocks := range prefab.Stacks {
+		for _, block := range blocks {
+			if block.Size < ui.QUARTER || block.Size > ui.MAX_BLOCK_SIZE {
+				return fmt.Errorf("prefab %s has a block of size %s", prefab.Name, block.Size)
+			}
+			if !colours.Has(block.Colour) {
+				return fmt.Errorf("prefab %s has a block of unknown colour %d", prefab.Name, block.Colour)
+			}
+		}
+	}
+	return nil
+}
+
+// ParsePrefab reads a prefab file, checking that its blocks fit its area and palette.
+func ParsePrefab(data []byte) (*Prefab, error) {
+	prefab := &Prefab{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(prefab); err != nil {
+		return nil, err
+	}
+	if err := prefab.validate(); err != nil {
+		return nil, err
+	}
+	return prefab, nil
+}
+
+// prefabFileName turns name into a file name, keeping letters, digits, dashes and underscores.
+func prefabFileName(name string) string {
+	file := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, name)
+	if file == "" {
+		file = "prefab"
+	}
+	return file + PREFAB_EXTENSION
+}
+
+// SavePrefab writes prefab into the library directory dir. It refuses to replace a file
+// that is already there, which may hold a prefab whose name gives the same file name.
+func SavePrefab(dir string, prefab *Prefab) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(prefab, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := prefabFileName(prefab.Name)
+	f, err := os.OpenFile(filepath.Join(dir, file), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if os.IsExist(err) {
+		return fmt.Errorf("a prefab is already saved as %s, choose another name", file)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	prefab.file = file
+	return nil
+}
+
+// LoadPrefabLibrary reads every prefab in dir, sorted by name and then file. Broken files are skipped
+// and returned as errors, and a missing directory is an empty library.
+func LoadPrefabLibrary(dir string) ([]*Prefab, []error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+	prefabs := []*Prefab{}
+	errs := []error{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != PREFAB_EXTENSION {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var prefab *Prefab
+			if prefab, err = ParsePrefab(data); err == nil {
+				prefab.file = entry.Name()
+				prefabs = append(prefabs, prefab)
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("reading %s: %w", path, err))
+	}
+	sort.Slice(prefabs, func(i, j int) bool {
+		if prefabs[i].Name != prefabs[j].Name {
+			return prefabs[i].Name < prefabs[j].Name
+		}
+		return prefabs[i].file < prefabs[j].file
+	})
+	return prefabs, errs
+}
+
+// SelectionPrefab copies the stacks in the area selected with the prefab tool, along
+// with the palette colours they use.
+func (b *Board) SelectionPrefab(name string) (*Prefab, error) {
+	if b.selection == nil {
+		return nil, errors.New("select an area with the prefab tool first")
+	}
+	x0, y0, x1, y1 := b.selection.bounds()
+	prefab := &Prefab{
+		Name:   strings.TrimSpace(name),
+		Width:  x1 - x0 + 1,
+		Length: y1 - y0 + 1,
+	}
+	used := map[ui.BlockOperation]bool{}
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			blocks := append([]Block{}, b.data[y][x].view().Blocks...)
+			for _, block := range blocks {
+				used[block.Colour] = true
+			}
+			prefab.Stacks = append(prefab.Stacks, blocks)
+		}
+	}
+	for _, colour := range b.palette.Colours() {
+		if used[colour.ID] {
+			prefab.Palette = append(prefab.Palette, colour)
+		}
+	}
+	if err := prefab.validate(); err != nil {
+		return nil, err
+	}
+	return prefab, nil
+}
+
+// prefabColours matches the prefab's colours to the board palette by name, or by the
+// nearest colour where the board has no colour of that name.
+func (b *Board) prefabColours(prefab *Prefab) map[ui.BlockOperation]ui.BlockOperation {
+	colours := map[ui.BlockOperation]ui.BlockOperation{}
+	for _, colour := range prefab.Palette {
+		id, ok := b.palette.Lookup(colour.Name)
+		if !ok {
+			id = b.nearestBlockColour(colour.Colour)
+		}
+		colours[colour.ID] = id
+	}
+	return colours
+}
+
+// prefabBlocks gives the blocks the stack at x, y of prefab places on this board, with
+// sizes the board colour does not come in changed to its nearest size.
+func (b *Board) prefabBlocks(prefab *Prefab, colours map[ui.BlockOperation]ui.BlockOperation, x int, y int) ([]Block, int) {
+	blocks := []Block{}
+	height := 0
+	for _, block := range prefab.stackAt(x, y) {
+		block.Colour = colours[block.Colour]
+		if !b.palette.HasSize(block.Colour, block.Size) {
+			block.Size = b.palette.NearestSize(block.Colour, block.Size)
+		}
+		blocks = append(blocks, block)
+		height += block.Size.GetHeight()
+	}
+	return blocks, height
+}
+
+// StampPrefab builds prefab on top of the stacks with its top left corner at x, y, as
+// one undo step. Parts off the board are dropped, and stacks that would pass the max
+// height or that a rule denies are skipped.
+func (b *Board) StampPrefab(prefab *Prefab, x int, y int) FillResult {
+	colours := b.prefabColours(prefab)
+	result := FillResult{}
+	b.BeginGroup()
+	for py := 0; py < prefab.Length; py++ {
+		for px := 0; px < prefab.Width; px++ {
+			blocks, height := b.prefabBlocks(prefab, colours, px, py)
+			if len(blocks) == 0 || !b.inBounds(x+px, y+py) {
+				continue
+			}
+			tileStack := b.data[y+py][x+px]
+			if tileStack.currentHeight+height > b.maxHeight {
+				result.TooTall++
+				continue
+			}
+			if !b.checkBlocks(tileStack, tileStack.view().Blocks, blocks) {
+				result.Denied++
+				continue
+			}
+			for _, block := range blocks {
+				b.submit(b.newPlaceEdit(tileStack, block.Size, block.Colour))
+			}
+			result.Changed++
+		}
+	}
+	b.EndGroup()
+	return result
+}
+
+// SetStamp chooses the prefab the prefab tool stamps, or nil to select an area instead.
+func (b *Board) SetStamp(prefab *Prefab) {
+	b.stamp = prefab
+}
+
+// updatePrefab drags out a selection, or stamps the chosen prefab centred on the
+// hovered stack. The delete button clears the selection or the chosen prefab.
+func (b *Board) updatePrefab(tileStack *TileStack, state *ui.State, handler *input.Handler) {
+	if b.stamp == nil {
+		switch {
+		case handler.ActionIsJustPressed(ui.ActionSelect):
+			b.selection = &Selection{startX: tileStack.x, startY: tileStack.y, endX: tileStack.x, endY: tileStack.y}
+		case handler.ActionIsPressed(ui.ActionSelect) && b.selection != nil:
+			b.selection.endX, b.selection.endY = tileStack.x, tileStack.y
+		case handler.ActionIsJustPressed(ui.ActionDelete):
+			b.selection = nil
+		}
+		return
+	}
+	prefab := b.stamp.Transform(state.PrefabRotation, state.PrefabMirror)
+	preview := &stampPreview{prefab: prefab, x: tileStack.x - prefab.Width/2, y: tileStack.y - prefab.Length/2}
+	b.preview = preview
+	if handler.ActionIsJustPressed(ui.ActionSelect) {
+		result := b.StampPrefab(prefab, preview.x, preview.y)
+		if result.Changed > 0 {
+			state.PlaySoundPitched(placeSound(ui.FULL))
+			state.Info(result.String())
+		} else if result.TooTall > 0 || result.Denied > 0 {
+			state.Warn(result.String())
+		}
+	} else if handler.ActionIsJustPressed(ui.ActionDelete) {
+		state.Prefab = ""
+	}
+}
+
+// renderPrefabTool outlines the selection and draws the prefab about to be stamped
+// faded over the board.
+func (b *Board) renderPrefabTool(screen *ebiten.Image, renderer ui.Renderer) {
+	if b.selection != nil && b.stamp == nil {
+		x0, y0, x1, y1 := b.selection.bounds()
+		left, top, right, bottom := float64(x0), float64(y0), float64(x1+1), float64(y1+1)
+		b.cellLine(screen, renderer, left, top, right, top, SELECTION_COLOUR)
+		b.cellLine(screen, renderer, right, top, right, bottom, SELECTION_COLOUR)
+		b.cellLine(screen, renderer, right, bottom, left, bottom, SELECTION_COLOUR)
+		b.cellLine(screen, renderer, left, bottom, left, top, SELECTION_COLOUR)
+	}
+	if b.preview == nil {
+		return
+	}
+	prefab := b.preview.prefab
+	colours := b.prefabColours(prefab)
+	geometry := b.tileset.TwoD
+	if renderer == ui.ISOMETRIC {
+		geometry = b.tileset.Iso
+	}
+	// Iso stacks are drawn back to front, so rows go down and columns go right to left.
+	for py := 0; py < prefab.Length; py++ {
+		for px := prefab.Width - 1; px >= 0; px-- {
+			x, y := b.preview.x+px, b.preview.y+py
+			if !b.inBounds(x, y) {
+				continue
+			}
+			tileStack := b.data[y][x]
+			blocks, height := b.prefabBlocks(prefab, colours, px, py)
+			if tileStack.currentHeight+height > b.maxHeight || !b.checkBlocks(tileStack, tileStack.view().Blocks, blocks) {
+				continue
+			}
+			top := tileStack.stack[tileStack.currentIndex]
+			point := *top.point2D
+			if renderer == ui.ISOMETRIC {
+				point = *top.pointIso
+			}
+			for _, block := range blocks {
+				point.Y -= float64(geometry.depth(block.Size))
+				sprite2D, spriteIso := b.sprites.get(block.Size)
+				sprite := sprite2D
+				if renderer == ui.ISOMETRIC {
+					sprite = spriteIso
+				}
+				drawOpts := tileDrawOptions(&Tile{height: block.Size}, sprite, &point, b.camera, b.palette.Colour(block.Colour))
+				drawOpts.ColorScale.ScaleAlpha(PREFAB_GHOST_ALPHA)
+				screen.DrawImage(sprite, drawOpts)
+			}
+		}
+	}
+}
+
+// PrefabThumbnail draws prefab in iso straight from its cells with the board's sprites,
+// scaled down to fit in a size x size square.
+func (b *Board) PrefabThumbnail(prefab *Prefab, size int) *ebiten.Image {
+	type sprite struct {
+		image  *ebiten.Image
+		tile   *Tile
+		point  Point
+		colour color.RGBA
+	}
+	palette := ui.NewPalette(prefab.Palette)
+	ground := newGroundTile(b.loader)
+	geometry := b.tileset.Iso
+	grounds, blocks := []sprite{}, []sprite{}
+	bounds := image.Rectangle{}
+	add := func(sprites []sprite, s sprite) []sprite {
+		x, y := int(math.Floor(s.point.X)), int(math.Floor(s.point.Y))
+		bounds = bounds.Union(image.Rect(x, y, x+s.image.Bounds().Dx(), y+s.image.Bounds().Dy()))
+		return append(sprites, s)
+	}
+	// Stacks are drawn back to front, so rows go down and columns go right to left.
+	for y := 0; y < prefab.Length; y++ {
+		for x := prefab.Width - 1; x >= 0; x-- {
+			isoX, isoY := calculateIsoCoord(&Point{}, geometry, x, y)
+			point := Point{X: isoX, Y: isoY}
+			grounds = add(grounds, sprite{ground.spriteIso, ground, point, color.RGBA{}})
+			for _, block := range prefab.stackAt(x, y) {
+				point.Y -= float64(geometry.depth(block.Size))
+				_, spriteIso := b.sprites.get(block.Size)
+				blocks = add(blocks, sprite{spriteIso, &Tile{height: block.Size}, point, palette.Colour(block.Colour)})
+			}
+		}
+	}
+
+	canvas := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	camera := &Point{X: float64(bounds.Min.X), Y: float64(bounds.Min.Y)}
+	for _, s := range append(grounds, blocks...) {
+		canvas.DrawImage(s.image, tileDrawOptions(s.tile, s.image, &s.point, camera, s.colour))
+	}
+
+	thumbnail := ebiten.NewImage(size, size)
+	scale := math.Min(1, math.Min(float64(size)/float64(bounds.Dx()), float64(size)/float64(bounds.Dy())))
+	drawOpts := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	drawOpts.GeoM.Scale(scale, scale)
+	drawOpts.GeoM.Translate((float64(size)-float64(bounds.Dx())*scale)/2, (float64(size)-float64(bounds.Dy())*scale)/2)
+	thumbnail.DrawImage(canvas, drawOpts)
+	return thumbnail
+}
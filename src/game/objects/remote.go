@@ -0,0 +1,58 @@
+package objects
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// RemoteHover is the cell another co-editing user is pointing at.
+type RemoteHover struct {
+	X      int
+	Y      int
+	Colour color.RGBA
+}
+
+func (b *Board) SetRemoteHovers(hovers []RemoteHover) {
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			tileStack.remoteHover = nil
+		}
+	}
+	for i := range hovers {
+		if b.inBounds(hovers[i].X, hovers[i].Y) {
+			b.data[hovers[i].Y][hovers[i].X].remoteHover = &hovers[i].Colour
+		}
+	}
+}
+
+// SetEditHandler routes local edits to handler instead of applying them, so that a
+// co-editing host can decide their order.
+func (b *Board) SetEditHandler(handler func(edit Edit)) {
+	b.editHandler = handler
+}
+
+// submit performs a local edit and makes it undoable.
+func (b *Board) submit(edit Edit) {
+	b.recordUndo(edit)
+	b.submitEdit(edit)
+}
+
+func (b *Board) submitEdit(edit Edit) {
+	if b.editHandler != nil {
+		b.editHandler(edit)
+		return
+	}
+	b.Apply(edit)
+}
+
+// tintRemoteHover blends the sprite halfway towards the remote user's colour.
+func tintRemoteHover(drawOpts *ebiten.DrawImageOptions, colour *color.RGBA) {
+	if colour == nil {
+		return
+	}
+	mix := func(c uint8) float32 {
+		return 0.5 + float32(c)/255/2
+	}
+	drawOpts.ColorScale.Scale(mix(colour.R), mix(colour.G), mix(colour.B), 1)
+}
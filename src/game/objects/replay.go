@@ -0,0 +1,391 @@
+package objects
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const (
+	REPLAY_MAGIC   = "BPRP"
+	REPLAY_VERSION = 1
+)
+
+var REPLAY_SPEEDS = []int{1, 2, 4, 8}
+
+type Replay struct {
+	Width     int
+	Height    int
+	Depth     int
+	MaxHeight int
+	Palette   []ui.PaletteColour
+	Edits     []Edit
+	Notes     []Note
+}
+
+func (b *Board) Replay() *Replay {
+	edits := make([]Edit, len(b.edits))
+	copy(edits, b.edits)
+	return &Replay{
+		Width:     b.width,
+		Height:    b.height,
+		Depth:     b.depth,
+		MaxHeight: b.maxHeight,
+		Palette:   append([]ui.PaletteColour(nil), b.palette.Colours()...),
+		Edits:     edits,
+		Notes:     b.Notes(),
+	}
+}
+
+// WriteReplay encodes replay as a header and the palette, followed by one varint packed
+// record per edit, with ticks stored as deltas from the previous edit, and the notes.
+func WriteReplay(w io.Writer, replay *Replay) error {
+	writer := bufio.NewWriter(w)
+	writer.WriteString(REPLAY_MAGIC)
+	writer.WriteByte(REPLAY_VERSION)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(v int) {
+		n := binary.PutUvarint(buf, uint64(v))
+		writer.Write(buf[:n])
+	}
+	writeUvarint(replay.Width)
+	writeUvarint(replay.Height)
+	writeUvarint(replay.Depth)
+	writeUvarint(replay.MaxHeight)
+	writeUvarint(len(replay.Palette))
+	for _, colour := range replay.Palette {
+		writeUvarint(int(colour.ID))
+		writeUvarint(len(colour.Name))
+		writer.WriteString(colour.Name)
+		writer.Write([]byte{colour.Colour.R, colour.Colour.G, colour.Colour.B})
+		writeUvarint(len(colour.Sizes))
+		for _, size := range colour.Sizes {
+			writeUvarint(int(size))
+		}
+	}
+	writeUvarint(len(replay.Edits))
+
+	previousTick := 0
+	for _, edit := range replay.Edits {
+		writeUvarint(edit.Tick - previousTick)
+		writeUvarint(edit.X)
+		writeUvarint(edit.Y)
+		writer.WriteByte(byte(edit.Operation) | byte(edit.Size)<<1)
+		writeUvarint(int(edit.Colour))
+		previousTick = edit.Tick
+	}
+	writeUvarint(len(replay.Notes))
+	for _, note := range replay.Notes {
+		writeUvarint(note.X)
+		writeUvarint(note.Y)
+		writeUvarint(len(note.Text))
+		writer.WriteString(note.Text)
+	}
+	return writer.Flush()
+}
+
+func ReadReplay(r io.Reader) (*Replay, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(REPLAY_MAGIC)+1)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(REPLAY_MAGIC)]) != REPLAY_MAGIC {
+		return nil, errors.New("not a replay file")
+	}
+	if version := header[len(REPLAY_MAGIC)]; version != REPLAY_VERSION {
+		return nil, fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	var err error
+	readUvarint := func() int {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = binary.ReadUvarint(reader)
+		return int(v)
+	}
+
+	replay := &Replay{
+		Width:     readUvarint(),
+		Height:    readUvarint(),
+		Depth:     readUvarint(),
+		MaxHeight: readUvarint(),
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckBoardSize(replay.Width, replay.Height, replay.Depth); err != nil {
+		return nil, fmt.Errorf("replay board: %w", err)
+	}
+	if replay.MaxHeight < 1 || replay.MaxHeight > MAX_BOARD_DEPTH*ui.FULL.GetHeight() {
+		return nil, fmt.Errorf("replay max height of %d is not between 1 and %d quarter blocks", replay.MaxHeight, MAX_BOARD_DEPTH*ui.FULL.GetHeight())
+	}
+	palette, paletteErr := readPalette(reader, readUvarint)
+	if paletteErr != nil {
+		return nil, paletteErr
+	}
+	replay.Palette = palette
+	count := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	tick := 0
+	for i := 0; i < count; i++ {
+		tick += readUvarint()
+		x := readUvarint()
+		y := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		packed, byteErr := reader.ReadByte()
+		if byteErr != nil {
+			return nil, byteErr
+		}
+		colour := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		size := ui.BlockSize(packed >> 1)
+		if size > ui.MAX_BLOCK_SIZE {
+			return nil, fmt.Errorf("replay block size %d is above %s", size, ui.MAX_BLOCK_SIZE)
+		}
+		replay.Edits = append(replay.Edits, Edit{
+			Tick:      tick,
+			X:         x,
+			Y:         y,
+			Operation: EditOperation(packed & 1),
+			Size:      size,
+			Colour:    ui.BlockOperation(colour),
+		})
+	}
+	notes, notesErr := readNotes(reader, readUvarint, replay.Width*replay.Height)
+	if notesErr != nil {
+		return nil, notesErr
+	}
+	replay.Notes = notes
+	if err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+func readNotes(reader *bufio.Reader, readUvarint func() int, stacks int) ([]Note, error) {
+	count := readUvarint()
+	if count > stacks {
+		return nil, fmt.Errorf("replay has %d notes for %d stacks", count, stacks)
+	}
+	var notes []Note
+	for i := 0; i < count; i++ {
+		x := readUvarint()
+		y := readUvarint()
+		length := readUvarint()
+		if length > MAX_NOTE_LENGTH {
+			return nil, fmt.Errorf("replay note is %d bytes", length)
+		}
+		text := make([]byte, length)
+		if _, err := io.ReadFull(reader, text); err != nil {
+			return nil, err
+		}
+		notes = append(notes, Note{X: x, Y: y, Text: string(text)})
+	}
+	return notes, nil
+}
+
+func readPalette(reader *bufio.Reader, readUvarint func() int) ([]ui.PaletteColour, error) {
+	count := readUvarint()
+	if count > ui.MAX_PALETTE_COLOURS {
+		return nil, fmt.Errorf("replay palette has %d colours", count)
+	}
+	var palette []ui.PaletteColour
+	for i := 0; i < count; i++ {
+		id := readUvarint()
+		length := readUvarint()
+		if length > ui.MAX_COLOUR_NAME_LENGTH {
+			return nil, fmt.Errorf("replay colour name is %d bytes", length)
+		}
+		name := make([]byte, length)
+		if _, err := io.ReadFull(reader, name); err != nil {
+			return nil, err
+		}
+		rgb := make([]byte, 3)
+		if _, err := io.ReadFull(reader, rgb); err != nil {
+			return nil, err
+		}
+		sizeCount := readUvarint()
+		if sizeCount > int(ui.MAX_BLOCK_SIZE) {
+			return nil, fmt.Errorf("replay colour has %d sizes", sizeCount)
+		}
+		sizes := make([]ui.BlockSize, sizeCount)
+		for j := range sizes {
+			sizes[j] = ui.BlockSize(readUvarint())
+		}
+		palette = append(palette, ui.PaletteColour{
+			ID:     ui.BlockOperation(id),
+			Name:   string(name),
+			Colour: color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255},
+			Sizes:  sizes,
+		})
+	}
+	if err := CheckPalette(palette); err != nil {
+		return nil, fmt.Errorf("replay palette: %w", err)
+	}
+	return palette, nil
+}
+
+// CheckPalette returns an error unless colours fit in a palette, with short names and
+// unique IDs between 1 and ui.MAX_COLOUR_ID.
+func CheckPalette(colours []ui.PaletteColour) error {
+	if len(colours) > ui.MAX_PALETTE_COLOURS {
+		return fmt.Errorf("%d colours is more than %d", len(colours), ui.MAX_PALETTE_COLOURS)
+	}
+	ids := make(map[ui.BlockOperation]bool)
+	for _, colour := range colours {
+		if colour.ID < 1 || colour.ID > ui.MAX_COLOUR_ID {
+			return fmt.Errorf("colour ID %d is not between 1 and %d", colour.ID, ui.MAX_COLOUR_ID)
+		}
+		if ids[colour.ID] {
+			return fmt.Errorf("colour ID %d is used twice", colour.ID)
+		}
+		ids[colour.ID] = true
+		if len(colour.Name) > ui.MAX_COLOUR_NAME_LENGTH {
+			return fmt.Errorf("colour name %q is longer than %d bytes", colour.Name, ui.MAX_COLOUR_NAME_LENGTH)
+		}
+		for _, size := range colour.Sizes {
+			if size < ui.QUARTER || size > ui.MAX_BLOCK_SIZE {
+				return fmt.Errorf("colour %q has block size %d", colour.Name, size)
+			}
+		}
+	}
+	return nil
+}
+
+func SaveReplay(path string, replay *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteReplay(f, replay)
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+type ReplayPlayer struct {
+	board   *Board
+	replay  *Replay
+	next    int
+	tick    int
+	speed   int
+	playing bool
+}
+
+// NewReplayPlayer clears board to the replay dimensions, max height, palette and notes
+// ready for playback. The board is left as it was if the replay is too big for it.
+func NewReplayPlayer(board *Board, replay *Replay) (*ReplayPlayer, error) {
+	if err := CheckBoardSize(replay.Width, replay.Height, replay.Depth); err != nil {
+		return nil, err
+	}
+	board.Reset(replay.Width, replay.Height, replay.Depth)
+	if err := board.SetMaxHeight(replay.MaxHeight); err != nil {
+		return nil, err
+	}
+	if len(replay.Palette) > 0 {
+		board.palette.Replace(replay.Palette)
+	}
+	board.SetNotes(replay.Notes)
+	return &ReplayPlayer{
+		board:   board,
+		replay:  replay,
+		speed:   REPLAY_SPEEDS[0],
+		playing: true,
+	}, nil
+}
+
+func (player *ReplayPlayer) applyUntil(tick int) {
+	for player.next < len(player.replay.Edits) && player.replay.Edits[player.next].Tick <= tick {
+		player.board.Apply(player.replay.Edits[player.next])
+		player.next++
+	}
+}
+
+func (player *ReplayPlayer) Update() {
+	if !player.playing {
+		return
+	}
+	player.tick += player.speed
+	player.applyUntil(player.tick)
+	if player.IsDone() {
+		player.playing = false
+	}
+}
+
+func (player *ReplayPlayer) Step() {
+	if player.IsDone() {
+		return
+	}
+	player.tick = player.replay.Edits[player.next].Tick
+	player.applyUntil(player.tick)
+}
+
+// Finish applies every remaining edit immediately.
+func (player *ReplayPlayer) Finish() {
+	player.applyUntil(math.MaxInt)
+	player.playing = false
+}
+
+func (player *ReplayPlayer) TogglePlaying() {
+	player.playing = !player.playing && !player.IsDone()
+}
+
+func (player *ReplayPlayer) CycleSpeed() {
+	for i, speed := range REPLAY_SPEEDS {
+		if speed == player.speed {
+			player.speed = REPLAY_SPEEDS[(i+1)%len(REPLAY_SPEEDS)]
+			return
+		}
+	}
+}
+
+func (player *ReplayPlayer) IsDone() bool {
+	return player.next >= len(player.replay.Edits)
+}
+
+func (player *ReplayPlayer) IsPlaying() bool {
+	return player.playing
+}
+
+func (player *ReplayPlayer) Speed() int {
+	return player.speed
+}
+
+func (player *ReplayPlayer) Progress() (int, int) {
+	return player.next, len(player.replay.Edits)
+}
+
+// ApplyReplay rebuilds board from replay in a single call, without ticking.
+func ApplyReplay(board *Board, replay *Replay) error {
+	player, err := NewReplayPlayer(board, replay)
+	if err != nil {
+		return err
+	}
+	player.Finish()
+	return nil
+}
@@ -0,0 +1,154 @@
+package objects
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// ReportCount is how many blocks of one colour and size the board uses.
+type ReportCount struct {
+	Colour string
+	Size   float64
+	Count  int
+}
+
+// ReportRow sums up one row of stacks.
+type ReportRow struct {
+	Row       int
+	Blocks    int
+	Occupied  int
+	Volume    float64
+	MaxHeight float64
+}
+
+// Report is a bill of materials for the board. Sizes, heights and volumes are in blocks,
+// and the mean height is over every cell, including empty ones.
+type Report struct {
+	Width      int
+	Length     int
+	Blocks     int
+	Occupied   int
+	Volume     float64
+	MaxHeight  float64
+	MeanHeight float64
+	Counts     []ReportCount
+	Rows       []ReportRow
+}
+
+func heightInBlocks(height int) float64 {
+	return float64(height) / float64(ui.FULL)
+}
+
+// Tallest is MaxHeight in quarter blocks.
+func (report *Report) Tallest() int {
+	return int(math.Round(report.MaxHeight * float64(ui.FULL)))
+}
+
+func (b *Board) Report() *Report {
+	report := &Report{Width: b.width, Length: b.height}
+	type key struct {
+		colour ui.BlockOperation
+		size   ui.BlockSize
+	}
+	counts := map[key]int{}
+	total := 0
+	for y, row := range b.data {
+		summary := ReportRow{Row: y}
+		for _, tileStack := range row {
+			for _, tile := range tileStack.stack[1:] {
+				counts[key{tile.colour, tile.height}]++
+				summary.Blocks++
+			}
+			if tileStack.currentIndex > 0 {
+				summary.Occupied++
+			}
+			summary.Volume += heightInBlocks(tileStack.currentHeight)
+			if height := heightInBlocks(tileStack.currentHeight); height > summary.MaxHeight {
+				summary.MaxHeight = height
+			}
+			total += tileStack.currentHeight
+		}
+		report.Blocks += summary.Blocks
+		report.Occupied += summary.Occupied
+		if summary.MaxHeight > report.MaxHeight {
+			report.MaxHeight = summary.MaxHeight
+		}
+		report.Rows = append(report.Rows, summary)
+	}
+	report.Volume = heightInBlocks(total)
+	if cells := b.width * b.height; cells > 0 {
+		report.MeanHeight = report.Volume / float64(cells)
+	}
+
+	// Colours are listed in palette order, then smallest size first.
+	order := map[ui.BlockOperation]int{}
+	for i, colour := range b.palette.Colours() {
+		order[colour.ID] = i
+	}
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].colour != keys[j].colour {
+			oi, iKnown := order[keys[i].colour]
+			oj, jKnown := order[keys[j].colour]
+			if iKnown != jKnown {
+				return iKnown
+			}
+			if iKnown {
+				return oi < oj
+			}
+			return keys[i].colour < keys[j].colour
+		}
+		return keys[i].size < keys[j].size
+	})
+	for _, k := range keys {
+		name := b.palette.Name(k.colour)
+		if name == "" {
+			name = fmt.Sprintf("colour %d", k.colour)
+		}
+		report.Counts = append(report.Counts, ReportCount{Colour: name, Size: heightInBlocks(k.size.GetHeight()), Count: counts[k]})
+	}
+	return report
+}
+
+func (report *Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteCSV writes the summary, the block counts and the rows as three tables, each with
+// a header and separated by an empty line.
+func (report *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	records := [][]string{
+		{"width", "length", "blocks", "occupied", "volume", "max_height", "mean_height"},
+		{strconv.Itoa(report.Width), strconv.Itoa(report.Length), strconv.Itoa(report.Blocks), strconv.Itoa(report.Occupied),
+			number(report.Volume), number(report.MaxHeight), strconv.FormatFloat(report.MeanHeight, 'f', 3, 64)},
+		{},
+		{"colour", "size", "count"},
+	}
+	for _, count := range report.Counts {
+		records = append(records, []string{count.Colour, number(count.Size), strconv.Itoa(count.Count)})
+	}
+	records = append(records, []string{}, []string{"row", "blocks", "occupied", "volume", "max_height"})
+	for _, row := range report.Rows {
+		records = append(records, []string{strconv.Itoa(row.Row), strconv.Itoa(row.Blocks), strconv.Itoa(row.Occupied), number(row.Volume), number(row.MaxHeight)})
+	}
+	return writer.WriteAll(records)
+}
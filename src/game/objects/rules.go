@@ -0,0 +1,201 @@
+package objects
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+type Block struct {
+	Size   ui.BlockSize
+	Colour ui.BlockOperation
+}
+
+// StackView is a read only copy of a stack handed to rules. Blocks run from the
+// ground up and exclude the ground tile.
+type StackView struct {
+	X      int
+	Y      int
+	Height int
+	Blocks []Block
+}
+
+func (view StackView) Top() (Block, bool) {
+	if len(view.Blocks) == 0 {
+		return Block{}, false
+	}
+	return view.Blocks[len(view.Blocks)-1], true
+}
+
+// Placement is a proposed block on Target. Neighbours holds the orthogonally adjacent
+// stacks that are on the board.
+type Placement struct {
+	Target      StackView
+	Neighbours  []StackView
+	Block       Block
+	MaxHeight   int
+	LayerHeight int
+}
+
+// NewHeight is the height of the target stack once the block is placed.
+func (placement *Placement) NewHeight() int {
+	return placement.Target.Height + placement.Block.Size.GetHeight()
+}
+
+// Rule allows or denies a placement. A denied placement with a reason raises an alert
+// showing it, while an empty reason denies silently.
+type Rule struct {
+	Name  string
+	Check func(placement *Placement) (bool, string)
+}
+
+var SELECT_RULE = Rule{Name: "select", Check: func(placement *Placement) (bool, string) {
+	return placement.Block.Colour != ui.SELECT, ""
+}}
+
+var MAX_HEIGHT_RULE = Rule{Name: "max-height", Check: func(placement *Placement) (bool, string) {
+	return placement.NewHeight() <= placement.MaxHeight, "MAX HEIGHT REACHED!"
+}}
+
+var LAYER_RULE = Rule{Name: "layer", Check: func(placement *Placement) (bool, string) {
+	return placement.NewHeight() <= placement.LayerHeight, "ABOVE THE CURRENT LAYER!"
+}}
+
+var NO_RED_ON_YELLOW_RULE = Rule{Name: "no-red-on-yellow", Check: func(placement *Placement) (bool, string) {
+	top, ok := placement.Target.Top()
+	return !(ok && top.Colour == ui.PLACE_YELLOW && placement.Block.Colour == ui.PLACE_RED), "NO RED ON TOP OF YELLOW!"
+}}
+
+// ADJACENT_SUPPORT_RULE stops a stack from rising more than a full block above all of
+// its neighbours.
+var ADJACENT_SUPPORT_RULE = Rule{Name: "adjacent-support", Check: func(placement *Placement) (bool, string) {
+	if placement.NewHeight() <= ui.FULL.GetHeight() {
+		return true, ""
+	}
+	for _, neighbour := range placement.Neighbours {
+		if placement.NewHeight()-neighbour.Height <= ui.FULL.GetHeight() {
+			return true, ""
+		}
+	}
+	return false, "NEEDS ADJACENT SUPPORT!"
+}}
+
+// HALF_ON_TOP_RULE stops anything being built on a block shorter than a full block.
+var HALF_ON_TOP_RULE = Rule{Name: "half-on-top", Check: func(placement *Placement) (bool, string) {
+	top, ok := placement.Target.Top()
+	return !(ok && top.Size < ui.FULL), "SHORT BLOCKS ONLY ON TOP!"
+}}
+
+var DEFAULT_RULES = []Rule{SELECT_RULE, MAX_HEIGHT_RULE, LAYER_RULE}
+
+// OPTIONAL_RULES can be switched on and off by name.
+var OPTIONAL_RULES = map[string]Rule{
+	NO_RED_ON_YELLOW_RULE.Name: NO_RED_ON_YELLOW_RULE,
+	ADJACENT_SUPPORT_RULE.Name: ADJACENT_SUPPORT_RULE,
+	HALF_ON_TOP_RULE.Name:      HALF_ON_TOP_RULE,
+}
+
+func OptionalRuleNames() []string {
+	names := []string{}
+	for name := range OPTIONAL_RULES {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ts *TileStack) view() StackView {
+	view := StackView{X: ts.x, Y: ts.y, Height: ts.currentHeight}
+	for _, tile := range ts.stack[1:] {
+		view.Blocks = append(view.Blocks, Block{Size: tile.height, Colour: tile.colour})
+	}
+	return view
+}
+
+func (b *Board) neighbours(tileStack *TileStack) []StackView {
+	views := []StackView{}
+	for _, offset := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		x, y := tileStack.x+offset[0], tileStack.y+offset[1]
+		if b.inBounds(x, y) {
+			views = append(views, b.data[y][x].view())
+		}
+	}
+	return views
+}
+
+// checkRules returns whether block may go on tileStack and, if not, the first reason.
+func (b *Board) checkRules(tileStack *TileStack, block Block, layerHeight int) (bool, string) {
+	placement := &Placement{
+		Target:      tileStack.view(),
+		Neighbours:  b.neighbours(tileStack),
+		Block:       block,
+		MaxHeight:   b.maxHeight,
+		LayerHeight: layerHeight,
+	}
+	return checkPlacement(b.rules, placement)
+}
+
+func checkPlacement(rules []Rule, placement *Placement) (bool, string) {
+	for _, rule := range rules {
+		if ok, reason := rule.Check(placement); !ok {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+func (b *Board) Rules() []Rule {
+	return b.rules
+}
+
+func (b *Board) HasRule(name string) bool {
+	for _, rule := range b.rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) AddRule(rule Rule) {
+	b.RemoveRule(rule.Name)
+	b.rules = append(b.rules, rule)
+}
+
+// SetOptionalRules switches on the optional rules named in names and every other one off.
+func (b *Board) SetOptionalRules(names []string) error {
+	for _, name := range names {
+		if _, ok := OPTIONAL_RULES[name]; !ok {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	for name := range OPTIONAL_RULES {
+		b.RemoveRule(name)
+	}
+	for _, name := range names {
+		b.AddRule(OPTIONAL_RULES[name])
+	}
+	return nil
+}
+
+// optionalRuleNames returns the names of the optional rules in rules.
+func optionalRuleNames(rules []Rule) []string {
+	names := []string{}
+	for _, rule := range rules {
+		if _, ok := OPTIONAL_RULES[rule.Name]; ok {
+			names = append(names, rule.Name)
+		}
+	}
+	return names
+}
+
+func (b *Board) RemoveRule(name string) {
+	rules := []Rule{}
+	for _, rule := range b.rules {
+		if rule.Name != name {
+			rules = append(rules, rule)
+		}
+	}
+	b.rules = rules
+}
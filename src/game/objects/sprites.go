@@ -0,0 +1,119 @@
+package objects
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// SpriteSizes are the dimensions tile sprites must have to line up with the board grid.
+// Iso blocks overlap the tile below them by one row.
+func (tileset Tileset) SpriteSizes() map[resource.ImageID]image.Point {
+	iso, twoD := tileset.Iso, tileset.TwoD
+	return map[resource.ImageID]image.Point{
+		assets.ImgGround2D:         {X: twoD.Width, Y: twoD.Height},
+		assets.ImgGroundIso:        {X: iso.Width, Y: iso.Height},
+		assets.ImgBlockCube2D:      {X: twoD.Width, Y: twoD.Height + twoD.depth(ui.FULL)},
+		assets.ImgBlockHalfCube2D:  {X: twoD.Width, Y: twoD.Height + twoD.depth(ui.HALF)},
+		assets.ImgBlockCubeIso:     {X: iso.Width, Y: iso.Height + iso.depth(ui.FULL) - 1},
+		assets.ImgBlockHalfCubeIso: {X: iso.Width, Y: iso.Height + iso.depth(ui.HALF) - 1},
+	}
+}
+
+// BlockSprites gives the sprites for each block size. Half and full blocks have their
+// own art, and the other sizes are cut down or stretched from the full block.
+type BlockSprites struct {
+	loader  *resource.Loader
+	tileset Tileset
+	iso     map[ui.BlockSize]*ebiten.Image
+	twoD    map[ui.BlockSize]*ebiten.Image
+}
+
+func newBlockSprites(loader *resource.Loader, tileset Tileset) *BlockSprites {
+	return &BlockSprites{
+		loader:  loader,
+		tileset: tileset,
+		iso:     map[ui.BlockSize]*ebiten.Image{},
+		twoD:    map[ui.BlockSize]*ebiten.Image{},
+	}
+}
+
+func (sprites *BlockSprites) get(blockSize ui.BlockSize) (*ebiten.Image, *ebiten.Image) {
+	switch blockSize {
+	case ui.FULL:
+		return sprites.loader.LoadImage(assets.ImgBlockCube2D).Data, sprites.loader.LoadImage(assets.ImgBlockCubeIso).Data
+	case ui.HALF:
+		return sprites.loader.LoadImage(assets.ImgBlockHalfCube2D).Data, sprites.loader.LoadImage(assets.ImgBlockHalfCubeIso).Data
+	}
+	if _, ok := sprites.iso[blockSize]; !ok {
+		iso, twoD := sprites.tileset.Iso, sprites.tileset.TwoD
+		sprites.twoD[blockSize] = ebiten.NewImage(twoD.Width, twoD.Height+twoD.depth(blockSize))
+		sprites.iso[blockSize] = ebiten.NewImage(iso.Width, iso.Height+iso.depth(blockSize)-1)
+		sprites.draw(blockSize)
+	}
+	return sprites.twoD[blockSize], sprites.iso[blockSize]
+}
+
+// Refresh redraws the sprites made from the full block, after it has been reloaded.
+func (sprites *BlockSprites) Refresh() {
+	for blockSize := range sprites.iso {
+		sprites.draw(blockSize)
+	}
+}
+
+func (sprites *BlockSprites) draw(blockSize ui.BlockSize) {
+	iso, twoD := sprites.tileset.Iso, sprites.tileset.TwoD
+	resizeBlockSprite(sprites.twoD[blockSize], sprites.loader.LoadImage(assets.ImgBlockCube2D).Data, twoD.Height,
+		func(x int) int { return twoD.Height })
+	// The side of an iso block starts at the lower edges of the top face's diamond.
+	resizeBlockSprite(sprites.iso[blockSize], sprites.loader.LoadImage(assets.ImgBlockCubeIso).Data, iso.Height,
+		func(x int) int {
+			if x >= iso.Width/2 {
+				x = iso.Width - 1 - x
+			}
+			return iso.Height/2 + x*iso.Height/iso.Width
+		})
+}
+
+// resizeBlockSprite redraws the full block sprite into dst with its sides cut down or
+// stretched to fit. Each column keeps the top of its side below the face and the bottom
+// of its side with its edge, so only the plain middle of the side changes. sideTop gives
+// the row where the side starts in a column, and faceHeight is the height of the face.
+func resizeBlockSprite(dst *ebiten.Image, full *ebiten.Image, faceHeight int, sideTop func(x int) int) {
+	dst.Clear()
+	w, fullHeight := full.Bounds().Dx(), full.Bounds().Dy()
+	fullSide := fullHeight - faceHeight
+	grow := dst.Bounds().Dy() - fullHeight
+	side := fullSide + grow
+	keep := side / 2
+	if grow > 0 {
+		keep = fullSide / 2
+	}
+	for x := 0; x < w; x++ {
+		drawColumn := func(y0 int, y1 int, at int, scale float64) {
+			if y1 <= y0 {
+				return
+			}
+			drawOpts := &ebiten.DrawImageOptions{}
+			drawOpts.GeoM.Scale(1, scale)
+			drawOpts.GeoM.Translate(float64(x), float64(at))
+			dst.DrawImage(full.SubImage(image.Rect(x, y0, x+1, y1)).(*ebiten.Image), drawOpts)
+		}
+		cut := sideTop(x) + keep
+		drawColumn(0, cut, 0, 1)
+		if grow > 0 {
+			drawColumn(cut, cut+1, cut, float64(grow))
+			drawColumn(cut, fullHeight, cut+grow, 1)
+		} else {
+			drawColumn(cut-grow, fullHeight, cut, 1)
+		}
+	}
+}
+
+// RefreshSprites redraws the block sprites made from textures that have been reloaded.
+func (b *Board) RefreshSprites() {
+	b.sprites.Refresh()
+}
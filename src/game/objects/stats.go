@@ -0,0 +1,47 @@
+package objects
+
+import (
+	"fmt"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// SetMaxHeight changes how tall stacks may grow, in quarter block units. It cannot go
+// below the tallest existing stack or above MAX_BOARD_DEPTH.
+func (b *Board) SetMaxHeight(maxHeight int) error {
+	if limit := MAX_BOARD_DEPTH * ui.FULL.GetHeight(); maxHeight > limit {
+		return fmt.Errorf("max height cannot be more than %s blocks", ui.FormatHeight(limit))
+	}
+	if tallest := b.Report().Tallest(); maxHeight < tallest || maxHeight < 1 {
+		return fmt.Errorf("max height must be at least %s blocks", ui.FormatHeight(tallest))
+	}
+	b.maxHeight = maxHeight
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			tileStack.maxHeight = maxHeight
+		}
+	}
+	b.buildSpace()
+	return nil
+}
+
+// Clear removes every block as a single undo step.
+func (b *Board) Clear() {
+	b.BeginGroup()
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			for i := tileStack.currentIndex; i > 0; i-- {
+				tile := tileStack.stack[i]
+				b.submit(Edit{
+					Tick:      b.tick,
+					X:         tileStack.x,
+					Y:         tileStack.y,
+					Operation: DELETE,
+					Size:      tile.height,
+					Colour:    tile.colour,
+				})
+			}
+		}
+	}
+	b.EndGroup()
+}
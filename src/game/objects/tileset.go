@@ -0,0 +1,74 @@
+package objects
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// TileGeometry is the pixel size of a renderer's ground tile and how far up a stack
+// moves for a full block.
+type TileGeometry struct {
+	Width     int
+	Height    int
+	FullDepth int
+}
+
+// Tileset is the geometry the sprites were drawn for, read from the tileset manifest
+// so that art at another size lines up with the board grid.
+type Tileset struct {
+	Iso  TileGeometry
+	TwoD TileGeometry
+}
+
+var DEFAULT_TILESET = Tileset{
+	Iso:  TileGeometry{Width: 32, Height: 16, FullDepth: 17},
+	TwoD: TileGeometry{Width: 24, Height: 18, FullDepth: 14},
+}
+
+// depth is how far up a stack moves for a block of blockSize, rounded to the nearest pixel.
+func (geometry TileGeometry) depth(blockSize ui.BlockSize) int {
+	return int(math.Round(float64(geometry.FullDepth) * float64(blockSize) / float64(ui.FULL)))
+}
+
+func (geometry TileGeometry) validate(name string, halves bool) error {
+	if geometry.Width <= 0 || geometry.Height <= 0 || geometry.FullDepth < int(ui.FULL) {
+		return fmt.Errorf("%s sizes must be positive, with a depth of at least %d", name, ui.FULL)
+	}
+	if halves && (geometry.Width%2 != 0 || geometry.Height%2 != 0) {
+		return fmt.Errorf("%s width and height must be even", name)
+	}
+	return nil
+}
+
+// ParseTileset reads a tileset manifest. Fields it leaves out keep their default,
+// and an invalid manifest gives the default tileset along with the error.
+func ParseTileset(data []byte) (Tileset, error) {
+	tileset := DEFAULT_TILESET
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&tileset); err != nil {
+		return DEFAULT_TILESET, fmt.Errorf("tileset: %w", err)
+	}
+	if err := tileset.Iso.validate("Iso", true); err != nil {
+		return DEFAULT_TILESET, fmt.Errorf("tileset: %w", err)
+	}
+	if err := tileset.TwoD.validate("TwoD", false); err != nil {
+		return DEFAULT_TILESET, fmt.Errorf("tileset: %w", err)
+	}
+	return tileset, nil
+}
+
+// LoadTileset reads the tileset manifest with read. A manifest that cannot be read or
+// is not JSON at all is left to the asset check to report, and gives the default tileset.
+func LoadTileset(read func(path string) ([]byte, error)) (Tileset, error) {
+	data, err := read(assets.RAW_RESOURCES[assets.RawTileset].Path)
+	if err != nil || !json.Valid(data) {
+		return DEFAULT_TILESET, nil
+	}
+	return ParseTileset(data)
+}
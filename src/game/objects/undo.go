@@ -0,0 +1,157 @@
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const UNDO_LIMIT = 100
+
+// BeginGroup collects every local edit until EndGroup into a single undo step. In a
+// shared session, where edits only land once the host relays them, Place, Remove and
+// Height work on a shadow copy of the board until then so later edits see earlier ones.
+func (b *Board) BeginGroup() {
+	b.grouping = true
+	b.group = nil
+	if b.editHandler != nil {
+		b.shadow = b.snapshot()
+	}
+}
+
+func (b *Board) EndGroup() {
+	b.grouping = false
+	if len(b.group) > 0 {
+		b.pushUndo(b.group)
+	}
+	b.group = nil
+	b.shadow = nil
+}
+
+func (b *Board) pushUndo(edits []Edit) {
+	b.undoStack = append(b.undoStack, edits)
+	if len(b.undoStack) > UNDO_LIMIT {
+		b.undoStack = b.undoStack[1:]
+	}
+}
+
+func (b *Board) recordUndo(edit Edit) {
+	if b.grouping {
+		b.group = append(b.group, edit)
+	} else {
+		b.pushUndo([]Edit{edit})
+	}
+}
+
+func (b *Board) CanUndo() bool {
+	return len(b.undoStack) > 0
+}
+
+// Undo reverses the most recent undo step, newest edit first. The step is dropped
+// instead if the rules or max height no longer allow the blocks it would put back, or,
+// in a shared session, if another user has changed the stacks it touched since.
+func (b *Board) Undo() error {
+	if !b.CanUndo() {
+		return nil
+	}
+	edits := b.undoStack[len(b.undoStack)-1]
+	b.undoStack = b.undoStack[:len(b.undoStack)-1]
+	inverses := make([]Edit, len(edits))
+	for i := range edits {
+		inverses[len(edits)-1-i] = edits[i].inverse(b.tick)
+	}
+	shadow := b.snapshot()
+	for _, inverse := range inverses {
+		if err := shadow.Apply(inverse); err != nil {
+			return fmt.Errorf("cannot undo, %s", err)
+		}
+	}
+	for _, inverse := range inverses {
+		b.submitEdit(inverse)
+	}
+	return nil
+}
+
+func (edit Edit) inverse(tick int) Edit {
+	inverse := edit
+	inverse.Tick = tick
+	if edit.Operation == PLACE {
+		inverse.Operation = DELETE
+	} else {
+		inverse.Operation = PLACE
+	}
+	return inverse
+}
+
+func (b *Board) stackAt(x int, y int) (*TileStack, error) {
+	if !b.inBounds(x, y) {
+		return nil, fmt.Errorf("cell (%d, %d) is outside the %dx%d board", x, y, b.width, b.height)
+	}
+	return b.data[y][x], nil
+}
+
+// Place adds a block to the stack at x, y as a local edit.
+func (b *Board) Place(x int, y int, blockSize ui.BlockSize, blockOperation ui.BlockOperation) error {
+	tileStack, err := b.stackAt(x, y)
+	if err != nil {
+		return err
+	}
+	if !b.palette.Has(blockOperation) {
+		return errors.New("unknown block colour")
+	}
+	if !b.palette.HasSize(blockOperation, blockSize) {
+		return fmt.Errorf("%s blocks do not come in size %s", b.palette.Name(blockOperation), blockSize)
+	}
+	edit := b.newPlaceEdit(tileStack, blockSize, blockOperation)
+	if b.shadow != nil {
+		if err := b.shadow.Apply(edit); err != nil {
+			return err
+		}
+		b.submit(edit)
+		return nil
+	}
+	// Scripts are not limited by the layer being viewed.
+	if ok, reason := b.checkRules(tileStack, Block{Size: blockSize, Colour: blockOperation}, b.maxHeight); !ok {
+		return fmt.Errorf("stack at (%d, %d): %s", x, y, strings.ToLower(strings.TrimSuffix(reason, "!")))
+	}
+	b.submit(edit)
+	return nil
+}
+
+// Remove deletes the top block of the stack at x, y as a local edit.
+func (b *Board) Remove(x int, y int) error {
+	tileStack, err := b.stackAt(x, y)
+	if err != nil {
+		return err
+	}
+	if b.shadow != nil {
+		top, ok := b.shadow.stacks[y][x].Top()
+		if !ok {
+			return fmt.Errorf("stack at (%d, %d) is empty", x, y)
+		}
+		edit := Edit{Tick: b.tick, X: x, Y: y, Operation: DELETE, Size: top.Size, Colour: top.Colour}
+		b.shadow.Apply(edit)
+		b.submit(edit)
+		return nil
+	}
+	if tileStack.currentIndex == 0 {
+		return fmt.Errorf("stack at (%d, %d) is empty", x, y)
+	}
+	b.submit(b.newDeleteEdit(tileStack))
+	return nil
+}
+
+// Height is the height of the stack at x, y, including edits made through the shadow
+// board of a shared session that the host has not relayed yet.
+func (b *Board) Height(x int, y int) (int, error) {
+	tileStack, err := b.stackAt(x, y)
+	if err != nil {
+		return 0, err
+	}
+	if b.shadow != nil {
+		return tileStack.stack[0].height.GetHeight() + b.shadow.stacks[y][x].Height, nil
+	}
+	return tileStack.currentHeight, nil
+}
@@ -0,0 +1,113 @@
+package objects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// Validator tracks the stacks of a board without any sprites and checks edits against
+// the board's rules, so that a co-editing host can reject edits before relaying them.
+type Validator struct {
+	width     int
+	height    int
+	maxHeight int
+	rules     []Rule
+	palette   *ui.Palette
+	stacks    [][]StackView
+}
+
+// NewValidator checks edits to an empty w x h board against the rules, palette and max
+// height of b as they are now.
+func (b *Board) NewValidator(w int, h int) *Validator {
+	v := &Validator{
+		width:     w,
+		height:    h,
+		maxHeight: b.maxHeight,
+		rules:     append([]Rule{}, b.rules...),
+		palette:   ui.NewPalette(b.palette.Colours()),
+		stacks:    make([][]StackView, h),
+	}
+	for y := range v.stacks {
+		v.stacks[y] = make([]StackView, w)
+		for x := range v.stacks[y] {
+			v.stacks[y][x] = StackView{X: x, Y: y}
+		}
+	}
+	return v
+}
+
+// snapshot is a validator holding the board's stacks as they are now.
+func (b *Board) snapshot() *Validator {
+	v := b.NewValidator(b.width, b.height)
+	for y, row := range b.data {
+		for x, tileStack := range row {
+			v.stacks[y][x] = tileStack.view()
+		}
+	}
+	return v
+}
+
+func (v *Validator) MaxHeight() int {
+	return v.maxHeight
+}
+
+func (v *Validator) Palette() []ui.PaletteColour {
+	return v.palette.Colours()
+}
+
+// OptionalRules returns the names of the optional rules edits are checked against.
+func (v *Validator) OptionalRules() []string {
+	return optionalRuleNames(v.rules)
+}
+
+func (v *Validator) neighbours(x int, y int) []StackView {
+	views := []StackView{}
+	for _, offset := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		nx, ny := x+offset[0], y+offset[1]
+		if ny >= 0 && ny < v.height && nx >= 0 && nx < v.width {
+			views = append(views, v.stacks[ny][nx])
+		}
+	}
+	return views
+}
+
+// Apply performs edit if it is valid, and otherwise returns why it is not. Deletes must
+// name the block on top of the stack, so that one made against an out of date board
+// cannot remove a block placed since.
+func (v *Validator) Apply(edit Edit) error {
+	if edit.Y < 0 || edit.Y >= v.height || edit.X < 0 || edit.X >= v.width {
+		return fmt.Errorf("cell (%d, %d) is outside the %dx%d board", edit.X, edit.Y, v.width, v.height)
+	}
+	stack := &v.stacks[edit.Y][edit.X]
+	block := Block{Size: edit.Size, Colour: edit.Colour}
+	switch edit.Operation {
+	case PLACE:
+		if !v.palette.HasSize(edit.Colour, edit.Size) {
+			return fmt.Errorf("no block of colour %d in size %s", edit.Colour, edit.Size)
+		}
+		placement := &Placement{
+			Target:      *stack,
+			Neighbours:  v.neighbours(edit.X, edit.Y),
+			Block:       block,
+			MaxHeight:   v.maxHeight,
+			LayerHeight: v.maxHeight,
+		}
+		if ok, reason := checkPlacement(v.rules, placement); !ok {
+			return fmt.Errorf("stack at (%d, %d): %s", edit.X, edit.Y, strings.ToLower(strings.TrimSuffix(reason, "!")))
+		}
+		stack.Blocks = append(append([]Block{}, stack.Blocks...), block)
+		stack.Height += edit.Size.GetHeight()
+	case DELETE:
+		top, ok := stack.Top()
+		if !ok || top != block {
+			return fmt.Errorf("stack at (%d, %d) no longer has that block on top", edit.X, edit.Y)
+		}
+		stack.Blocks = stack.Blocks[:len(stack.Blocks)-1]
+		stack.Height -= edit.Size.GetHeight()
+	default:
+		return fmt.Errorf("unknown edit operation %d", edit.Operation)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package objects
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const (
+	VOX_MAGIC   = "VOX "
+	VOX_VERSION = 150
+	// VOX_MAX_SIZE is the most voxels a model can have along each axis, as coordinates
+	// are single bytes.
+	VOX_MAX_SIZE = 256
+	// VOX_MAX_CHUNK is the largest chunk read, enough for a model full of voxels.
+	VOX_MAX_CHUNK = 4*VOX_MAX_SIZE*VOX_MAX_SIZE*VOX_MAX_SIZE + 4
+	// VOX_UNIT is the height of one voxel, so a half block is one voxel and a full block two.
+	VOX_UNIT = ui.HALF
+)
+
+type voxChunk struct {
+	id       string
+	content  []byte
+	children []byte
+}
+
+func writeVoxChunk(w io.Writer, chunk voxChunk) {
+	w.Write([]byte(chunk.id))
+	binary.Write(w, binary.LittleEndian, uint32(len(chunk.content)))
+	binary.Write(w, binary.LittleEndian, uint32(len(chunk.children)))
+	w.Write(chunk.content)
+	w.Write(chunk.children)
+}
+
+// WriteVox encodes the board as a MagicaVoxel model with one voxel per VOX_UNIT, each
+// taking the colour of the block at its base. Board rows run along the model's y axis
+// and stack height along z.
+func (b *Board) WriteVox(w io.Writer) error {
+	grid := b.voxels().scaled(VOX_UNIT.GetHeight())
+	if grid.width > VOX_MAX_SIZE || grid.length > VOX_MAX_SIZE || grid.height > VOX_MAX_SIZE {
+		return fmt.Errorf("a %dx%dx%d model is too big for a vox file, which allows %d voxels along each side", grid.width, grid.length, grid.height, VOX_MAX_SIZE)
+	}
+	size := make([]byte, 12)
+	binary.LittleEndian.PutUint32(size[0:], uint32(grid.width))
+	binary.LittleEndian.PutUint32(size[4:], uint32(grid.length))
+	binary.LittleEndian.PutUint32(size[8:], uint32(grid.height))
+
+	voxels := []byte{0, 0, 0, 0}
+	count := 0
+	for z := 0; z < grid.height; z++ {
+		for y := 0; y < grid.length; y++ {
+			for x := 0; x < grid.width; x++ {
+				if colour := grid.get(x, y, z); colour != ui.SELECT {
+					voxels = append(voxels, byte(x), byte(grid.length-1-y), byte(z), byte(colour))
+					count++
+				}
+			}
+		}
+	}
+	binary.LittleEndian.PutUint32(voxels, uint32(count))
+
+	// Palette entry i is referenced by colour index i+1, which is the palette colour ID.
+	palette := make([]byte, 256*4)
+	for _, colour := range b.palette.Colours() {
+		c := colour.Colour
+		copy(palette[(int(colour.ID)-1)*4:], []byte{c.R, c.G, c.B, c.A})
+	}
+
+	children := &bytes.Buffer{}
+	writeVoxChunk(children, voxChunk{id: "SIZE", content: size})
+	writeVoxChunk(children, voxChunk{id: "XYZI", content: voxels})
+	writeVoxChunk(children, voxChunk{id: "RGBA", content: palette})
+
+	writer := bufio.NewWriter(w)
+	writer.WriteString(VOX_MAGIC)
+	binary.Write(writer, binary.LittleEndian, uint32(VOX_VERSION))
+	writeVoxChunk(writer, voxChunk{id: "MAIN", children: children.Bytes()})
+	return writer.Flush()
+}
+
+func readVoxChunk(r io.Reader) (voxChunk, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return voxChunk{}, err
+	}
+	contentSize, childrenSize := binary.LittleEndian.Uint32(header[4:]), binary.LittleEndian.Uint32(header[8:])
+	if contentSize > VOX_MAX_CHUNK || childrenSize > 2*VOX_MAX_CHUNK {
+		return voxChunk{}, fmt.Errorf("vox chunk %q is too big", header[:4])
+	}
+	chunk := voxChunk{id: string(header[:4])}
+	var err error
+	if chunk.content, err = readVoxBytes(r, contentSize); err != nil {
+		return voxChunk{}, err
+	}
+	if chunk.children, err = readVoxBytes(r, childrenSize); err != nil {
+		return voxChunk{}, err
+	}
+	return chunk, nil
+}
+
+// readVoxBytes reads n bytes, only growing the buffer as they arrive so that a chunk
+// claiming to be bigger than the file does not allocate its claimed size.
+func readVoxBytes(r io.Reader, n uint32) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < int(n) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+func (b *Board) nearestBlockColour(c color.RGBA) ui.BlockOperation {
+	nearest, nearestDistance := ui.SELECT, -1
+	for _, colour := range b.palette.Colours() {
+		target := colour.Colour
+		dr, dg, db := int(c.R)-int(target.R), int(c.G)-int(target.G), int(c.B)-int(target.B)
+		if distance := dr*dr + dg*dg + db*db; nearestDistance < 0 || distance < nearestDistance {
+			nearest, nearestDistance = colour.ID, distance
+		}
+	}
+	return nearest
+}
+
+// ReadVox replaces the board with the first model in a MagicaVoxel file, one voxel per
+// VOX_UNIT. Each voxel column is filled solid up to its top voxel, capped at the board's max height, with
+// gaps taking the colour of the voxel above, and then split into blocks of the sizes
+// each colour has.
+func (b *Board) ReadVox(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	if string(header[:4]) != VOX_MAGIC {
+		return errors.New("not a vox file")
+	}
+	main, err := readVoxChunk(reader)
+	if err != nil {
+		return err
+	}
+	if main.id != "MAIN" {
+		return fmt.Errorf("expected MAIN chunk, found %q", main.id)
+	}
+
+	var size, voxels []byte
+	palette := map[byte]ui.BlockOperation{}
+	children := bytes.NewReader(main.children)
+	for children.Len() > 0 {
+		chunk, err := readVoxChunk(children)
+		if err != nil {
+			return err
+		}
+		switch {
+		case chunk.id == "SIZE" && size == nil:
+			size = chunk.content
+		case chunk.id == "XYZI" && voxels == nil:
+			voxels = chunk.content
+		case chunk.id == "RGBA":
+			for i := 0; i+3 < len(chunk.content) && i/4 < 255; i += 4 {
+				c := color.RGBA{R: chunk.content[i], G: chunk.content[i+1], B: chunk.content[i+2], A: 255}
+				palette[byte(i/4+1)] = b.nearestBlockColour(c)
+			}
+		}
+	}
+	if len(size) < 12 || len(voxels) < 4 {
+		return errors.New("vox file has no model")
+	}
+
+	w := int(binary.LittleEndian.Uint32(size[0:]))
+	h := int(binary.LittleEndian.Uint32(size[4:]))
+	if w < 1 || h < 1 {
+		return errors.New("vox model is empty")
+	}
+	if w > VOX_MAX_SIZE || h > VOX_MAX_SIZE {
+		return fmt.Errorf("vox model is %dx%d, more than %d voxels across", w, h, VOX_MAX_SIZE)
+	}
+	count := int(binary.LittleEndian.Uint32(voxels))
+	if len(voxels) < 4+count*4 {
+		return errors.New("vox model is truncated")
+	}
+
+	maxHeight := b.maxHeight
+	unit := VOX_UNIT.GetHeight()
+	columns := make([][]ui.BlockOperation, w*h)
+	for i := 0; i < count; i++ {
+		v := voxels[4+i*4 : 8+i*4]
+		x, y, z := int(v[0]), h-1-int(v[1]), int(v[2])*unit
+		if x >= w || y < 0 || z >= maxHeight {
+			continue
+		}
+		colour, ok := palette[v[3]]
+		if !ok {
+			colours := b.palette.Colours()
+			colour = colours[int(v[3])%len(colours)].ID
+		}
+		column := columns[x+y*w]
+		top := z + unit
+		if top > maxHeight {
+			top = maxHeight
+		}
+		for len(column) < top {
+			column = append(column, ui.SELECT)
+		}
+		for ; z < top; z++ {
+			column[z] = colour
+		}
+		columns[x+y*w] = column
+	}
+
+	b.Reset(w, h, b.depth)
+	b.SetMaxHeight(maxHeight)
+	for i, column := range columns {
+		for z := len(column) - 2; z >= 0; z-- {
+			if column[z] == ui.SELECT {
+				column[z] = column[z+1]
+			}
+		}
+		for _, edit := range quantiseColumn(i%w, i/w, column, b.palette, b.maxHeight) {
+			edit.Tick = b.tick
+			b.Apply(edit)
+		}
+	}
+	return nil
+}
+
+func (b *Board) SaveVox(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return b.WriteVox(f)
+}
+
+func (b *Board) LoadVox(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return b.ReadVox(f)
+}
@@ -0,0 +1,93 @@
+package objects
+
+import (
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// voxelGrid is the board sampled in quarter block steps. Empty voxels hold ui.SELECT.
+type voxelGrid struct {
+	width  int
+	length int
+	height int
+	data   []ui.BlockOperation
+}
+
+func (grid *voxelGrid) get(x int, y int, z int) ui.BlockOperation {
+	if x < 0 || y < 0 || z < 0 || x >= grid.width || y >= grid.length || z >= grid.height {
+		return ui.SELECT
+	}
+	return grid.data[x+y*grid.width+z*grid.width*grid.length]
+}
+
+func (b *Board) voxels() *voxelGrid {
+	grid := &voxelGrid{
+		width:  b.width,
+		length: b.height,
+		height: b.maxHeight,
+		data:   make([]ui.BlockOperation, b.width*b.height*b.maxHeight),
+	}
+	for _, row := range b.data {
+		for _, tileStack := range row {
+			z := 0
+			for _, tile := range tileStack.stack[1:] {
+				for i := 0; i < tile.height.GetHeight() && z < grid.height; i++ {
+					grid.data[tileStack.x+tileStack.y*grid.width+z*grid.width*grid.length] = tile.colour
+					z++
+				}
+			}
+		}
+	}
+	return grid
+}
+
+// scaled samples the grid every unit quarters, taking each new voxel from the lowest
+// quarter it covers.
+func (grid *voxelGrid) scaled(unit int) *voxelGrid {
+	scaled := &voxelGrid{
+		width:  grid.width,
+		length: grid.length,
+		height: (grid.height + unit - 1) / unit,
+	}
+	scaled.data = make([]ui.BlockOperation, scaled.width*scaled.length*scaled.height)
+	for z := 0; z < scaled.height; z++ {
+		for y := 0; y < scaled.length; y++ {
+			for x := 0; x < scaled.width; x++ {
+				scaled.data[x+y*scaled.width+z*scaled.width*scaled.length] = grid.get(x, y, z*unit)
+			}
+		}
+	}
+	return scaled
+}
+
+// quantiseColumn turns a column of voxel colours into stack edits, using the tallest
+// size of each colour that fits the rest of its run, or its shortest size if none does.
+// Blocks never reach above maxHeight; the column stops at the first one that cannot fit.
+func quantiseColumn(x int, y int, column []ui.BlockOperation, palette *ui.Palette, maxHeight int) []Edit {
+	edits := []Edit{}
+	for z := 0; z < len(column); {
+		run := 1
+		for z+run < len(column) && column[z+run] == column[z] {
+			run++
+		}
+		size := palette.NearestSize(column[z], ui.QUARTER)
+		for _, available := range palette.Sizes(column[z]) {
+			if available.GetHeight() <= run {
+				size = available
+			}
+		}
+		if size.GetHeight() > maxHeight-z {
+			size = ui.FLAT
+			for _, available := range palette.Sizes(column[z]) {
+				if available.GetHeight() <= maxHeight-z {
+					size = available
+				}
+			}
+			if size == ui.FLAT {
+				break
+			}
+		}
+		edits = append(edits, Edit{X: x, Y: y, Operation: PLACE, Size: size, Colour: column[z]})
+		z += size.GetHeight()
+	}
+	return edits
+}
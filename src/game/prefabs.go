@@ -0,0 +1,60 @@
+package game
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// loadPrefabs reads the prefab library and hands the panel a thumbnail of each prefab.
+// Prefabs are keyed by file name, as two files may hold prefabs with the same name.
+func (g *Game) loadPrefabs() {
+	g.prefabs = map[string]*objects.Prefab{}
+	g.prefabEntries = nil
+	if g.prefabDir == "" {
+		return
+	}
+	prefabs, errs := objects.LoadPrefabLibrary(g.prefabDir)
+	for _, err := range errs {
+		g.ui.State.Error(err.Error())
+	}
+	for _, prefab := range prefabs {
+		g.addPrefab(prefab)
+	}
+	g.ui.SetPrefabs(g.prefabEntries)
+}
+
+// addPrefab adds prefab to the library, drawing only its own thumbnail.
+func (g *Game) addPrefab(prefab *objects.Prefab) {
+	g.prefabs[prefab.File()] = prefab
+	g.prefabEntries = append(g.prefabEntries, ui.PrefabEntry{
+		File:      prefab.File(),
+		Name:      prefab.Name,
+		Thumbnail: g.board.PrefabThumbnail(prefab, ui.PREFAB_THUMBNAIL_SIZE),
+	})
+}
+
+func (g *Game) savePrefab(name string) error {
+	if g.prefabDir == "" {
+		return errors.New("there is no prefab library directory")
+	}
+	prefab, err := g.board.SelectionPrefab(name)
+	if err != nil {
+		return err
+	}
+	if err := objects.SavePrefab(g.prefabDir, prefab); err != nil {
+		return err
+	}
+	g.addPrefab(prefab)
+	sort.SliceStable(g.prefabEntries, func(i, j int) bool {
+		if g.prefabEntries[i].Name != g.prefabEntries[j].Name {
+			return g.prefabEntries[i].Name < g.prefabEntries[j].Name
+		}
+		return g.prefabEntries[i].File < g.prefabEntries[j].File
+	})
+	g.ui.SetPrefabs(g.prefabEntries)
+	g.ui.State.Info("saved prefab " + prefab.Name)
+	return nil
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/solarlune/resolv"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+func (g *Game) newPlaybackHandlers() *ui.PlaybackHandlers {
+	return &ui.PlaybackHandlers{
+		SaveHandler: func(args *widget.ButtonClickedEventArgs) {
+			if err := objects.SaveReplay(g.replayPath, g.board.Replay()); err != nil {
+				g.ui.State.Error("Saving replay failed: " + err.Error())
+			} else {
+				g.ui.State.Info("Replay saved to " + g.replayPath)
+			}
+		},
+		LoadHandler: func(args *widget.ButtonClickedEventArgs) {
+			g.startPlayback()
+		},
+		PlayPauseHandler: func(args *widget.ButtonClickedEventArgs) {
+			if g.replayPlayer != nil {
+				g.replayPlayer.TogglePlaying()
+			}
+		},
+		StepHandler: func(args *widget.ButtonClickedEventArgs) {
+			if g.replayPlayer != nil {
+				g.replayPlayer.Step()
+			}
+		},
+		SpeedHandler: func(args *widget.ButtonClickedEventArgs) {
+			if g.replayPlayer != nil {
+				g.replayPlayer.CycleSpeed()
+			}
+		},
+		ExitHandler: func(args *widget.ButtonClickedEventArgs) {
+			g.replayPlayer = nil
+		},
+	}
+}
+
+func (g *Game) startPlayback() {
+	if g.client != nil {
+		g.ui.State.Warn("Replays cannot be loaded during a shared session")
+		return
+	}
+	replay, err := objects.LoadReplay(g.replayPath)
+	if err != nil {
+		g.ui.State.Error("Loading replay failed: " + err.Error())
+		return
+	}
+	if g.replayPlayer, err = objects.NewReplayPlayer(g.board, replay); err != nil {
+		g.ui.State.Error("Loading replay failed: " + err.Error())
+	}
+}
+
+func (g *Game) updatePlayback() {
+	state := g.ui.State
+	state.Playback = g.replayPlayer != nil
+	if g.replayPlayer == nil {
+		return
+	}
+	g.replayPlayer.Update()
+	state.PlaybackPlaying = g.replayPlayer.IsPlaying()
+	state.PlaybackSpeed = g.replayPlayer.Speed()
+	state.PlaybackProgress, state.PlaybackTotal = g.replayPlayer.Progress()
+}
+
+// loadHeadlessBoard rebuilds the board stored in a replay without opening a window.
+func loadHeadlessBoard(replayPath string) (*objects.Board, error) {
+	replay, err := objects.LoadReplay(replayPath)
+	if err != nil {
+		return nil, err
+	}
+	board := objects.NewBoard(replay.Width, replay.Height, replay.Depth, resolv.NewObject(0, 0, 1, 1), newHeadlessLoader())
+	if err := objects.ApplyReplay(board, replay); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// RunHeadlessReplay writes the stack heights of the board stored in a replay to out,
+// one row per line.
+func RunHeadlessReplay(replayPath string, out io.Writer) error {
+	board, err := loadHeadlessBoard(replayPath)
+	if err != nil {
+		return err
+	}
+	for _, row := range board.Heights() {
+		for i, height := range row {
+			if i > 0 {
+				fmt.Fprint(out, " ")
+			}
+			fmt.Fprint(out, height)
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
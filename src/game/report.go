@@ -0,0 +1,95 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const REPORT_FILE = "report"
+
+var REPORT_FORMATS = map[string]func(report *objects.Report, w io.Writer) error{
+	"csv":  (*objects.Report).WriteCSV,
+	"json": (*objects.Report).WriteJSON,
+}
+
+func reportWriter(format string) (func(report *objects.Report, w io.Writer) error, error) {
+	write, ok := REPORT_FORMATS[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q, use csv or json", format)
+	}
+	return write, nil
+}
+
+// RunReport writes a bill of materials for the board stored in a replay to out.
+func RunReport(replayPath string, format string, out io.Writer) error {
+	write, err := reportWriter(format)
+	if err != nil {
+		return err
+	}
+	board, err := loadHeadlessBoard(replayPath)
+	if err != nil {
+		return err
+	}
+	return write(board.Report(), out)
+}
+
+// exportReport writes the board's report to path, in the format its extension names.
+func (g *Game) exportReport(path string) error {
+	write, err := reportWriter(strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(g.board.Report(), f)
+}
+
+func (g *Game) newStatsHandlers() *ui.StatsHandlers {
+	return &ui.StatsHandlers{
+		Summary: g.reportSummary,
+		Export: func(format string) {
+			path := REPORT_FILE + "." + format
+			if err := g.exportReport(path); err != nil {
+				g.ui.State.Error("Exporting report failed: " + err.Error())
+			} else {
+				g.ui.State.Info("Report saved to " + path)
+			}
+		},
+	}
+}
+
+// reportSummary gives the totals and the block counts of each colour as lines for the
+// stats panel.
+func (g *Game) reportSummary() []string {
+	report := g.board.Report()
+	lines := []string{
+		fmt.Sprintf("BLOCKS %d  VOLUME %s", report.Blocks, formatBlocks(report.Volume)),
+		fmt.Sprintf("OCCUPIED %d/%d", report.Occupied, report.Width*report.Length),
+		fmt.Sprintf("MAX %s  MEAN %.2f", formatBlocks(report.MaxHeight), report.MeanHeight),
+	}
+	colours := []string{}
+	sizes := map[string][]string{}
+	for _, count := range report.Counts {
+		if _, ok := sizes[count.Colour]; !ok {
+			colours = append(colours, count.Colour)
+		}
+		sizes[count.Colour] = append(sizes[count.Colour], fmt.Sprintf("%sx%d", formatBlocks(count.Size), count.Count))
+	}
+	for _, colour := range colours {
+		lines = append(lines, strings.ToUpper(colour)+"  "+strings.Join(sizes[colour], " "))
+	}
+	return lines
+}
+
+func formatBlocks(v float64) string {
+	return ui.FormatHeight(int(v * float64(ui.FULL)))
+}
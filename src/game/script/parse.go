@@ -0,0 +1,401 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Error reports a problem on a single line of a script.
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Message)
+}
+
+func errorf(line int, format string, args ...interface{}) *Error {
+	return &Error{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	NUMBER tokenKind = iota
+	IDENT
+	SYMBOL
+	END
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value int
+}
+
+var SYMBOLS = []string{"..", "==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ",", "="}
+
+func tokenize(line int, source string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case c == '#':
+			i = len(source)
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(source) && unicode.IsDigit(rune(source[i])) {
+				i++
+			}
+			value, err := strconv.Atoi(source[start:i])
+			if err != nil {
+				return nil, errorf(line, "bad number %q", source[start:i])
+			}
+			tokens = append(tokens, token{kind: NUMBER, text: source[start:i], value: value})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: IDENT, text: source[start:i]})
+		default:
+			matched := false
+			for _, symbol := range SYMBOLS {
+				if strings.HasPrefix(source[i:], symbol) {
+					tokens = append(tokens, token{kind: SYMBOL, text: symbol})
+					i += len(symbol)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errorf(line, "unexpected character %q", c)
+			}
+		}
+	}
+	return append(tokens, token{kind: END}), nil
+}
+
+type statement interface {
+	line() int
+}
+
+type letStatement struct {
+	lineNumber int
+	name       string
+	value      expression
+}
+
+type forStatement struct {
+	lineNumber int
+	name       string
+	from       expression
+	to         expression
+	body       []statement
+}
+
+type ifStatement struct {
+	lineNumber int
+	condition  expression
+	body       []statement
+	elseBody   []statement
+}
+
+type expressionStatement struct {
+	lineNumber int
+	value      expression
+}
+
+func (s *letStatement) line() int        { return s.lineNumber }
+func (s *forStatement) line() int        { return s.lineNumber }
+func (s *ifStatement) line() int         { return s.lineNumber }
+func (s *expressionStatement) line() int { return s.lineNumber }
+
+type expression interface{}
+
+type numberExpression struct {
+	value int
+}
+
+type identExpression struct {
+	name string
+}
+
+type unaryExpression struct {
+	op      string
+	operand expression
+}
+
+type binaryExpression struct {
+	op    string
+	left  expression
+	right expression
+}
+
+type callExpression struct {
+	name string
+	args []expression
+}
+
+// Lower binds weaker; every binary operator is left associative.
+var PRECEDENCE = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type lineParser struct {
+	line   int
+	tokens []token
+	pos    int
+}
+
+func (p *lineParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *lineParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != END {
+		p.pos++
+	}
+	return t
+}
+
+func (p *lineParser) isSymbol(text string) bool {
+	t := p.peek()
+	return t.kind == SYMBOL && t.text == text
+}
+
+func (p *lineParser) expect(text string) error {
+	if !p.isSymbol(text) {
+		return errorf(p.line, "expected %q", text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *lineParser) expectEnd() error {
+	if t := p.peek(); t.kind != END {
+		return errorf(p.line, "unexpected %q", t.text)
+	}
+	return nil
+}
+
+func (p *lineParser) expectIdent() (string, error) {
+	t := p.next()
+	if t.kind != IDENT {
+		return "", errorf(p.line, "expected a name")
+	}
+	return t.text, nil
+}
+
+func (p *lineParser) expression(minPrecedence int) (expression, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		precedence, ok := PRECEDENCE[t.text]
+		if t.kind != SYMBOL || !ok || precedence < minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.expression(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpression{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *lineParser) operand() (expression, error) {
+	t := p.next()
+	switch {
+	case t.kind == NUMBER:
+		return &numberExpression{value: t.value}, nil
+	case t.kind == SYMBOL && (t.text == "-" || t.text == "!"):
+		operand, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpression{op: t.text, operand: operand}, nil
+	case t.kind == SYMBOL && t.text == "(":
+		value, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		return value, p.expect(")")
+	case t.kind == IDENT && p.isSymbol("("):
+		p.next()
+		call := &callExpression{name: t.text}
+		for !p.isSymbol(")") {
+			if len(call.args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.expression(0)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+		}
+		p.next()
+		return call, nil
+	case t.kind == IDENT:
+		return &identExpression{name: t.text}, nil
+	case t.kind == END:
+		return nil, errorf(p.line, "unexpected end of line")
+	}
+	return nil, errorf(p.line, "unexpected %q", t.text)
+}
+
+type parser struct {
+	lines  []string
+	pos    int
+	errors []error
+}
+
+// block parses statements until one of the terminating keywords, which it returns.
+func (p *parser) block(terminators ...string) ([]statement, string) {
+	statements := []statement{}
+	for p.pos < len(p.lines) {
+		lineNumber := p.pos + 1
+		tokens, err := tokenize(lineNumber, p.lines[p.pos])
+		p.pos++
+		if err != nil {
+			p.errors = append(p.errors, err)
+			continue
+		}
+		first := tokens[0]
+		if first.kind == END {
+			continue
+		}
+		if first.kind == IDENT {
+			for _, terminator := range terminators {
+				if first.text == terminator {
+					if len(tokens) > 2 {
+						p.errors = append(p.errors, errorf(lineNumber, "unexpected %q", tokens[1].text))
+					}
+					return statements, terminator
+				}
+			}
+		}
+		s, err := p.statement(&lineParser{line: lineNumber, tokens: tokens})
+		if err != nil {
+			p.errors = append(p.errors, err)
+			continue
+		}
+		if s != nil {
+			statements = append(statements, s)
+		}
+	}
+	return statements, ""
+}
+
+func (p *parser) statement(lp *lineParser) (statement, error) {
+	first := lp.peek()
+	if first.kind == IDENT {
+		switch first.text {
+		case "let":
+			lp.next()
+			return p.assignment(lp)
+		case "for":
+			return p.forBlock(lp)
+		case "if":
+			return p.ifBlock(lp)
+		case "end", "else":
+			return nil, errorf(lp.line, "%q without a matching block", first.text)
+		}
+		if lp.tokens[1].kind == SYMBOL && lp.tokens[1].text == "=" {
+			return p.assignment(lp)
+		}
+	}
+	value, err := lp.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	return &expressionStatement{lineNumber: lp.line, value: value}, lp.expectEnd()
+}
+
+func (p *parser) assignment(lp *lineParser) (statement, error) {
+	name, err := lp.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := lp.expect("="); err != nil {
+		return nil, err
+	}
+	value, err := lp.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	return &letStatement{lineNumber: lp.line, name: name, value: value}, lp.expectEnd()
+}
+
+func (p *parser) forBlock(lp *lineParser) (statement, error) {
+	lp.next()
+	s := &forStatement{lineNumber: lp.line}
+	headerErr := p.forHeader(lp, s)
+	var terminator string
+	if s.body, terminator = p.block("end"); terminator == "" {
+		return nil, errorf(lp.line, "\"for\" is missing its \"end\"")
+	}
+	return s, headerErr
+}
+
+func (p *parser) forHeader(lp *lineParser, s *forStatement) error {
+	var err error
+	if s.name, err = lp.expectIdent(); err != nil {
+		return err
+	}
+	if in := lp.next(); in.kind != IDENT || in.text != "in" {
+		return errorf(lp.line, "expected \"in\"")
+	}
+	if s.from, err = lp.expression(0); err != nil {
+		return err
+	}
+	if err := lp.expect(".."); err != nil {
+		return err
+	}
+	if s.to, err = lp.expression(0); err != nil {
+		return err
+	}
+	return lp.expectEnd()
+}
+
+func (p *parser) ifBlock(lp *lineParser) (statement, error) {
+	lp.next()
+	s := &ifStatement{lineNumber: lp.line}
+	condition, headerErr := lp.expression(0)
+	if headerErr == nil {
+		headerErr = lp.expectEnd()
+	}
+	s.condition = condition
+	var terminator string
+	s.body, terminator = p.block("else", "end")
+	if terminator == "else" {
+		s.elseBody, terminator = p.block("end")
+	}
+	if terminator == "" {
+		return nil, errorf(lp.line, "\"if\" is missing its \"end\"")
+	}
+	return s, headerErr
+}
+
+// parse checks the whole script and returns every syntax error found, at most one per line.
+func parse(source string) ([]statement, []error) {
+	p := &parser{lines: strings.Split(source, "\n")}
+	statements, _ := p.block()
+	return statements, p.errors
+}
@@ -0,0 +1,274 @@
+package script
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+const MAX_STEPS = 100000
+
+// CONSTANTS are checked after variables, then the names of the board's palette colours.
+// Block sizes and heights are in quarter blocks.
+var CONSTANTS = map[string]int{
+	"quarter": int(ui.QUARTER),
+	"half":    int(ui.HALF),
+	"full":    int(ui.FULL),
+}
+
+type interpreter struct {
+	board     *objects.Board
+	out       io.Writer
+	variables map[string]int
+	steps     int
+	errors    []error
+	errorLine map[int]bool
+}
+
+type builtin func(in *interpreter, args []int) (int, error)
+
+var BUILTINS = map[string]struct {
+	arity int
+	call  builtin
+}{
+	"place": {4, func(in *interpreter, args []int) (int, error) {
+		return 0, in.board.Place(args[0], args[1], ui.BlockSize(args[3]), ui.BlockOperation(args[2]))
+	}},
+	"remove": {2, func(in *interpreter, args []int) (int, error) {
+		return 0, in.board.Remove(args[0], args[1])
+	}},
+	"height": {2, func(in *interpreter, args []int) (int, error) {
+		return in.board.Height(args[0], args[1])
+	}},
+	"width": {0, func(in *interpreter, args []int) (int, error) {
+		w, _ := in.board.Dimensions()
+		return w, nil
+	}},
+	"length": {0, func(in *interpreter, args []int) (int, error) {
+		_, h := in.board.Dimensions()
+		return h, nil
+	}},
+	"maxheight": {0, func(in *interpreter, args []int) (int, error) {
+		return in.board.MaxHeight(), nil
+	}},
+	"print": {-1, func(in *interpreter, args []int) (int, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			values[i] = arg
+		}
+		fmt.Fprintln(in.out, values...)
+		return 0, nil
+	}},
+}
+
+// Run executes source against board as a single undo step. A script with syntax errors is
+// not run at all; otherwise a failing statement is skipped and the rest still runs. At most
+// one error is reported for each line.
+func Run(source string, board *objects.Board, out io.Writer) []error {
+	statements, errs := parse(source)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	in := &interpreter{
+		board:     board,
+		out:       out,
+		variables: map[string]int{},
+		errorLine: map[int]bool{},
+	}
+	board.BeginGroup()
+	defer board.EndGroup()
+	in.block(statements)
+	return in.errors
+}
+
+func RunFile(path string, board *objects.Board, out io.Writer) []error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return []error{err}
+	}
+	return Run(string(source), board, out)
+}
+
+func (in *interpreter) report(line int, err error) {
+	if in.errorLine[line] {
+		return
+	}
+	in.errorLine[line] = true
+	if scriptErr, ok := err.(*Error); ok {
+		in.errors = append(in.errors, scriptErr)
+	} else {
+		in.errors = append(in.errors, &Error{Line: line, Message: err.Error()})
+	}
+}
+
+// step counts one statement or loop iteration, returning false once the step limit is hit
+// so that loops unwind.
+func (in *interpreter) step(line int) bool {
+	in.steps++
+	if in.steps > MAX_STEPS {
+		in.report(line, errorf(line, "script exceeded %d steps", MAX_STEPS))
+		return false
+	}
+	return true
+}
+
+func (in *interpreter) block(statements []statement) bool {
+	for _, s := range statements {
+		if !in.step(s.line()) || !in.statement(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (in *interpreter) statement(s statement) bool {
+	switch s := s.(type) {
+	case *letStatement:
+		value, err := in.eval(s.line(), s.value)
+		if err != nil {
+			in.report(s.line(), err)
+			return true
+		}
+		in.variables[s.name] = value
+	case *expressionStatement:
+		if _, err := in.eval(s.line(), s.value); err != nil {
+			in.report(s.line(), err)
+		}
+	case *ifStatement:
+		condition, err := in.eval(s.line(), s.condition)
+		if err != nil {
+			in.report(s.line(), err)
+			return true
+		}
+		if condition != 0 {
+			return in.block(s.body)
+		}
+		return in.block(s.elseBody)
+	case *forStatement:
+		from, err := in.eval(s.line(), s.from)
+		if err != nil {
+			in.report(s.line(), err)
+			return true
+		}
+		to, err := in.eval(s.line(), s.to)
+		if err != nil {
+			in.report(s.line(), err)
+			return true
+		}
+		for i := from; i < to; i++ {
+			in.variables[s.name] = i
+			// Iterations count as well, so that a loop with an empty body still stops.
+			if !in.step(s.line()) || !in.block(s.body) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (in *interpreter) eval(line int, e expression) (int, error) {
+	switch e := e.(type) {
+	case *numberExpression:
+		return e.value, nil
+	case *identExpression:
+		if value, ok := in.variables[e.name]; ok {
+			return value, nil
+		}
+		if value, ok := CONSTANTS[e.name]; ok {
+			return value, nil
+		}
+		if colour, ok := in.board.Palette().Lookup(e.name); ok {
+			return int(colour), nil
+		}
+		return 0, errorf(line, "unknown name %q", e.name)
+	case *unaryExpression:
+		value, err := in.eval(line, e.operand)
+		if err != nil {
+			return 0, err
+		}
+		if e.op == "-" {
+			return -value, nil
+		}
+		return boolToInt(value == 0), nil
+	case *binaryExpression:
+		return in.evalBinary(line, e)
+	case *callExpression:
+		fn, ok := BUILTINS[e.name]
+		if !ok {
+			return 0, errorf(line, "unknown function %q", e.name)
+		}
+		if fn.arity >= 0 && len(e.args) != fn.arity {
+			return 0, errorf(line, "%s takes %d arguments, got %d", e.name, fn.arity, len(e.args))
+		}
+		args := make([]int, len(e.args))
+		for i, arg := range e.args {
+			value, err := in.eval(line, arg)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = value
+		}
+		return fn.call(in, args)
+	}
+	return 0, errorf(line, "cannot evaluate expression")
+}
+
+func (in *interpreter) evalBinary(line int, e *binaryExpression) (int, error) {
+	left, err := in.eval(line, e.left)
+	if err != nil {
+		return 0, err
+	}
+	// && and || short circuit so that guards such as "x < width() && height(x, y) > 0" work.
+	if e.op == "&&" && left == 0 {
+		return 0, nil
+	}
+	if e.op == "||" && left != 0 {
+		return 1, nil
+	}
+	right, err := in.eval(line, e.right)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return 0, errorf(line, "division by zero")
+		}
+		if e.op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "==":
+		return boolToInt(left == right), nil
+	case "!=":
+		return boolToInt(left != right), nil
+	case "<":
+		return boolToInt(left < right), nil
+	case "<=":
+		return boolToInt(left <= right), nil
+	case ">":
+		return boolToInt(left > right), nil
+	case ">=":
+		return boolToInt(left >= right), nil
+	case "&&", "||":
+		return boolToInt(right != 0), nil
+	}
+	return 0, errorf(line, "unknown operator %q", e.op)
+}
@@ -0,0 +1,21 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/script"
+)
+
+// runScript builds on the current board from a script file as one undoable step,
+// logging each failing line.
+func (g *Game) runScript(path string) {
+	errs := script.RunFile(path, g.board, os.Stdout)
+	for _, err := range errs {
+		log.Printf("%s: %v", path, err)
+	}
+	if len(errs) > 0 {
+		g.ui.State.Error(fmt.Sprintf("%s: %d lines failed, see log", path, len(errs)))
+	}
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+	"github.com/timothy-ch-cheung/go-game-block-placement/ui"
+)
+
+// ApplyWindowSettings sets up the window before the game starts.
+func ApplyWindowSettings(settings config.Settings) {
+	ebiten.SetWindowSize(config.ScreenWidth*settings.Scale, config.ScreenHeight*settings.Scale)
+	ebiten.SetWindowSizeLimits(config.MinScreenWidth*settings.Scale, config.MinScreenHeight*settings.Scale, -1, -1)
+	ebiten.SetFullscreen(settings.Fullscreen)
+	ebiten.SetVsyncEnabled(settings.VSync)
+}
+
+// RunSettings are the saved settings with the command line overrides in options applied.
+func (options Options) RunSettings() config.Settings {
+	settings := options.Settings
+	if options.Scale > 0 {
+		settings.Scale = options.Scale
+	}
+	settings.PixelPerfect = settings.PixelPerfect || options.PixelPerfect
+	return settings
+}
+
+// persistentSettings are the settings to save, which keep the saved values where the
+// command line overrides are still in effect.
+func (g *Game) persistentSettings() config.Settings {
+	settings := g.settings
+	if g.overrides.Scale > 0 && settings.Scale == g.overrides.Scale {
+		settings.Scale = g.savedSettings.Scale
+	}
+	if g.overrides.PixelPerfect && settings.PixelPerfect {
+		settings.PixelPerfect = g.savedSettings.PixelPerfect
+	}
+	return settings
+}
+
+// updateSettings applies whatever changed in the settings since the last frame,
+// and saves them once the settings menu is closed.
+func (g *Game) updateSettings() {
+	settings := &g.ui.State.Settings
+	if g.inputHandler.ActionIsJustPressed(ui.ActionToggleFullscreen) {
+		settings.Fullscreen = !settings.Fullscreen
+	}
+
+	if settings.Fullscreen != g.settings.Fullscreen {
+		ebiten.SetFullscreen(settings.Fullscreen)
+	}
+	if settings.VSync != g.settings.VSync {
+		ebiten.SetVsyncEnabled(settings.VSync)
+	}
+	if settings.Background != g.settings.Background {
+		// Draw recreates the background in the new colour.
+		g.background = nil
+	}
+	if settings.Scale != g.settings.Scale {
+		// The window keeps showing the same area of the screen at the new scale.
+		ebiten.SetWindowSizeLimits(config.MinScreenWidth*settings.Scale, config.MinScreenHeight*settings.Scale, -1, -1)
+		ebiten.SetWindowSize(g.screenWidth*settings.Scale, g.screenHeight*settings.Scale)
+	}
+	g.settings = *settings
+
+	if !g.ui.State.SettingsOpen && g.persistentSettings() != g.savedSettings {
+		g.saveSettings()
+	}
+}
+
+func (g *Game) saveSettings() {
+	g.savedSettings = g.persistentSettings()
+	if g.settingsPath == "" {
+		return
+	}
+	if err := config.SaveSettings(g.settingsPath, g.savedSettings); err != nil {
+		g.ui.State.Error("Could not save settings: " + err.Error())
+	}
+}
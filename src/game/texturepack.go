@@ -0,0 +1,42 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/objects"
+)
+
+// TEXTURE_PACK_POLL_TICKS is how often the texture pack directory is checked for changes.
+const TEXTURE_PACK_POLL_TICKS = 30
+
+func (g *Game) updateTexturePack() {
+	if g.texturePack == nil {
+		return
+	}
+	g.ticks++
+	if g.ticks%TEXTURE_PACK_POLL_TICKS == 0 {
+		if reloaded := g.texturePack.Reload(g.loader); reloaded > 0 {
+			g.board.RefreshSprites()
+			g.ui.State.Info(fmt.Sprintf("Reloaded %d textures from %s", reloaded, g.texturePack.Dir()))
+		}
+	}
+	g.reportTexturePackProblems()
+}
+
+func (g *Game) reportTexturePackProblems() {
+	for _, err := range g.texturePack.Problems() {
+		g.ui.State.Warn(err.Error())
+	}
+}
+
+func openTexturePack(dir string) *assets.TexturePack {
+	if dir == "" {
+		return nil
+	}
+	pack := assets.NewTexturePack(dir)
+	// Sprites are checked against the pack's own tileset, which may be for larger art.
+	tileset, _ := objects.LoadTileset(pack.Read)
+	pack.SetSpriteSizes(tileset.SpriteSizes())
+	return pack
+}
@@ -2,22 +2,25 @@ module github.com/timothy-ch-cheung/go-game-block-placement
 
 go 1.18
 
+require (
+	github.com/ebitenui/ebitenui v0.5.4
+	github.com/hajimehoshi/ebiten/v2 v2.5.8
+	github.com/quasilyte/ebitengine-input v0.8.0
+	github.com/quasilyte/ebitengine-resource v0.5.0
+	github.com/solarlune/resolv v0.6.1
+	golang.org/x/image v0.10.0
+)
+
 require (
 	github.com/ebitengine/purego v0.4.0 // indirect
-	github.com/ebitenui/ebitenui v0.5.4 // indirect
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
-	github.com/hajimehoshi/ebiten/v2 v2.5.8 // indirect
 	github.com/hajimehoshi/oto/v2 v2.4.1 // indirect
 	github.com/jezek/xgb v1.1.0 // indirect
 	github.com/jfreymuth/oggvorbis v1.0.5 // indirect
 	github.com/jfreymuth/vorbis v1.0.2 // indirect
 	github.com/quartercastle/vector v0.1.3 // indirect
-	github.com/quasilyte/ebitengine-input v0.8.0 // indirect
-	github.com/quasilyte/ebitengine-resource v0.5.0 // indirect
 	github.com/quasilyte/gmath v0.0.0-20221217210116-fba37a2e15c7 // indirect
-	github.com/solarlune/resolv v0.6.1 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
-	golang.org/x/image v0.10.0 // indirect
 	golang.org/x/mobile v0.0.0-20230427221453-e8d11dd0ba41 // indirect
 	golang.org/x/sync v0.2.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
@@ -10,10 +12,89 @@ import (
 )
 
 func main() {
-	ebiten.SetWindowSize(config.ScreenWidth*config.Scale, config.ScreenHeight*config.Scale)
+	replayPath := flag.String("replay", "", "replay file to play back on launch")
+	headless := flag.Bool("headless", false, "rebuild the -replay board without a window and print its stack heights")
+	report := flag.String("report", "", "rebuild the -replay board without a window and print its block counts and heights as csv or json")
+	hostAddr := flag.String("host", "", "host a co-editing session on this address, e.g. :7777")
+	joinAddr := flag.String("join", "", "join the co-editing session at this address")
+	scriptPath := flag.String("script", "", "run a board script on launch")
+	scale := flag.Int("scale", 0, "whole number of window pixels per game pixel, overriding the saved setting for this run")
+	pixelPerfect := flag.Bool("pixel-perfect", false, "only scale the game by whole numbers of screen pixels, without changing the saved setting")
+	texturePack := flag.String("texture-pack", "", "directory of images, sounds and fonts overriding the built in ones, overriding the saved setting")
+	prefabDir := flag.String("prefabs", "", "directory of the prefab library, instead of the one in the user config directory")
+	checkAssets := flag.Bool("check-assets", false, "decode every asset, including the texture pack in use, list any problems and exit non-zero if there were some")
+	flag.Parse()
+
+	if (*headless || *report != "") && *replayPath == "" {
+		log.Fatal("-headless and -report rebuild a board from a replay, so they need -replay")
+	}
+	if *headless {
+		if err := game.RunHeadlessReplay(*replayPath, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *report != "" {
+		if err := game.RunReport(*replayPath, *report, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	settingsPath, err := config.SettingsPath()
+	if err != nil {
+		log.Print(err)
+	}
+	prefabLibrary, err := config.PrefabLibraryPath()
+	if err != nil {
+		log.Print(err)
+	}
+	if *prefabDir != "" {
+		prefabLibrary = *prefabDir
+	}
+	settings := config.DefaultSettings()
+	if settingsPath != "" {
+		if settings, err = config.LoadSettings(settingsPath); err != nil {
+			log.Print(err)
+		}
+	}
+	texturePackDir := settings.TexturePack
+	if *texturePack != "" {
+		texturePackDir = *texturePack
+	}
+	if *checkAssets {
+		if err := game.CheckAssets(texturePackDir, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	options := game.Options{
+		ReplayPath:    config.DefaultReplayPath,
+		StartPlayback: *replayPath != "",
+		HostAddr:      *hostAddr,
+		JoinAddr:      *joinAddr,
+		ScriptPath:    *scriptPath,
+		Settings:      settings,
+		SettingsPath:  settingsPath,
+		TexturePack:   texturePackDir,
+		PrefabDir:     prefabLibrary,
+		PixelPerfect:  *pixelPerfect,
+	}
+	if *scale > 0 && *scale <= config.MAX_SCALE {
+		options.Scale = *scale
+	}
+	if *replayPath != "" {
+		options.ReplayPath = *replayPath
+	}
+
+	game.ApplyWindowSettings(options.RunSettings())
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Game Block Placement Demo")
 
-	game := game.NewGame()
+	game, err := game.NewGame(options)
+	if err != nil {
+		log.Fatal(err)
+	}
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"io"
+	"log"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+const DEFAULT_VOLUME = 80
+
+// sound is a sound effect played pitch times faster, and so higher, than recorded.
+type sound struct {
+	id    resource.AudioID
+	pitch float64
+}
+
+type AudioPlayer struct {
+	loader  *resource.Loader
+	pitched map[sound]*audio.Player
+}
+
+func newAudioPlayer(loader *resource.Loader) *AudioPlayer {
+	return &AudioPlayer{loader: loader, pitched: map[sound]*audio.Player{}}
+}
+
+func (player *AudioPlayer) play(s sound, state *State) {
+	if state.Muted {
+		return
+	}
+	effect := player.loader.LoadAudio(s.id)
+	audioPlayer := effect.Player
+	if s.pitch != 1 {
+		audioPlayer = player.pitchedPlayer(s, effect.Player)
+	}
+	audioPlayer.SetVolume(effect.Volume * float64(state.Volume) / 100)
+	audioPlayer.Rewind()
+	audioPlayer.Play()
+}
+
+// pitchedPlayer decodes the sound resampled to play at its pitch, falling back to
+// fallback if it cannot be decoded. Players are kept for each sound and pitch.
+func (player *AudioPlayer) pitchedPlayer(s sound, fallback *audio.Player) *audio.Player {
+	if audioPlayer, ok := player.pitched[s]; ok {
+		return audioPlayer
+	}
+	audioPlayer := fallback
+	context := audio.CurrentContext()
+	path := assets.AUDIO_RESOURCES[s.id].Path
+	r := player.loader.OpenAssetFunc(path)
+	defer r.Close()
+	// Played back at the context's rate, a sound resampled to a lower rate runs faster.
+	if stream, err := wav.DecodeWithSampleRate(int(float64(context.SampleRate())/s.pitch), r); err != nil {
+		log.Printf("decoding %s at pitch %.2f: %v", path, s.pitch, err)
+	} else if data, err := io.ReadAll(stream); err != nil {
+		log.Printf("decoding %s at pitch %.2f: %v", path, s.pitch, err)
+	} else {
+		audioPlayer = context.NewPlayerFromBytes(data)
+	}
+	player.pitched[s] = audioPlayer
+	return audioPlayer
+}
+
+func (player *AudioPlayer) update(state *State) {
+	for _, s := range state.sounds {
+		player.play(s, state)
+	}
+	state.sounds = state.sounds[:0]
+}
+
+func (state *State) PlaySound(id resource.AudioID) {
+	state.PlaySoundPitched(id, 1)
+}
+
+// PlaySoundPitched plays id pitch times faster and higher than recorded, or slower and
+// lower for a pitch below 1.
+func (state *State) PlaySoundPitched(id resource.AudioID, pitch float64) {
+	state.sounds = append(state.sounds, sound{id: id, pitch: pitch})
+}
+
+func newVolumeControls(state *State, loader *resource.Loader) *widget.Container {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 8}),
+		)),
+	)
+
+	var muteToggled widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+		state.Muted = args.State == widget.WidgetChecked
+	}
+	container.AddChild(newTextButton("MUTE", loader,
+		widget.ButtonOpts.ToggleMode(),
+		widget.ButtonOpts.StateChangedHandler(muteToggled),
+	))
+
+	volumeSlider := newSlider(0, 100, func(args *widget.SliderChangedEventArgs) {
+		state.Volume = args.Current
+	}, loader)
+	volumeSlider.Current = state.Volume
+	container.AddChild(volumeSlider)
+
+	return container
+}
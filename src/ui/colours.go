@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+// BlockColourButtons is the block toolbar, with a button for each palette colour.
+type BlockColourButtons struct {
+	container     *widget.Container
+	loader        *resource.Loader
+	togglePalette func()
+	ids           []BlockOperation
+	colours       []color.RGBA
+	images        [][2]*ebiten.Image
+}
+
+func newBlockColourButtons(state *State, togglePalette func(), loader *resource.Loader) *BlockColourButtons {
+	buttons := &BlockColourButtons{
+		container: widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout()),
+		),
+		loader:        loader,
+		togglePalette: togglePalette,
+	}
+	state.BlockOperation = new(BlockOperation)
+	buttons.build(state)
+	return buttons
+}
+
+func (buttons *BlockColourButtons) build(state *State) {
+	loader := buttons.loader
+	buttons.container.RemoveChildren()
+	buttons.ids = buttons.ids[:0]
+	buttons.colours = buttons.colours[:0]
+	buttons.images = buttons.images[:0]
+
+	elements := []widget.RadioGroupElement{}
+	active := 0
+	addButton := func(blockOperation BlockOperation, selectedImg *ebiten.Image, idleImg *ebiten.Image) {
+		var changed widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
+			if int(args.State) > 0 {
+				*state.BlockOperation = blockOperation
+			}
+		}
+		if blockOperation == *state.BlockOperation {
+			active = len(elements)
+		}
+		checkbox := newCheckbox(&changed, selectedImg, idleImg, loader.LoadImage(assets.ImgPanelBtnDisabled).Data)
+		buttons.container.AddChild(checkbox)
+		elements = append(elements, checkbox)
+	}
+
+	addButton(SELECT, loader.LoadImage(assets.ImgCursorBtnSelected).Data, loader.LoadImage(assets.ImgCursorBtnIdle).Data)
+	for _, colour := range state.Palette.Colours() {
+		images := [2]*ebiten.Image{
+			tintedBlockButton(loader, assets.ImgBlockBtnSelected, colour.Colour),
+			tintedBlockButton(loader, assets.ImgBlockBtnIdle, colour.Colour),
+		}
+		addButton(colour.ID, images[0], images[1])
+		buttons.ids = append(buttons.ids, colour.ID)
+		buttons.colours = append(buttons.colours, colour.Colour)
+		buttons.images = append(buttons.images, images)
+	}
+
+	radioGroup := widget.NewRadioGroup(
+		widget.RadioGroupOpts.Elements(elements...),
+	)
+	radioGroup.SetActive(elements[active])
+	if active == 0 {
+		*state.BlockOperation = SELECT
+	}
+
+	buttons.container.AddChild(newTextButton("PAL", loader,
+		widget.ButtonOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter})),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			buttons.togglePalette()
+		}),
+	))
+}
+
+// update rebuilds the buttons when colours are added or reordered, and otherwise
+// retints them in place so dragging a colour slider does not allocate.
+func (buttons *BlockColourButtons) update(state *State) {
+	colours := state.Palette.Colours()
+	if len(colours) != len(buttons.ids) {
+		buttons.build(state)
+		return
+	}
+	for i, colour := range colours {
+		if colour.ID != buttons.ids[i] {
+			buttons.build(state)
+			return
+		}
+	}
+	for i, colour := range colours {
+		if colour.Colour != buttons.colours[i] {
+			buttons.colours[i] = colour.Colour
+			tintBlockButton(buttons.images[i][0], buttons.loader, assets.ImgBlockBtnSelected, colour.Colour)
+			tintBlockButton(buttons.images[i][1], buttons.loader, assets.ImgBlockBtnIdle, colour.Colour)
+		}
+	}
+}
+
+func tintedBlockButton(loader *resource.Loader, frame resource.ImageID, colour color.RGBA) *ebiten.Image {
+	bounds := loader.LoadImage(frame).Data.Bounds()
+	img := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	tintBlockButton(img, loader, frame, colour)
+	return img
+}
+
+// tintBlockButton draws the greyscale swatch in colour with the untinted frame on top.
+func tintBlockButton(img *ebiten.Image, loader *resource.Loader, frame resource.ImageID, colour color.RGBA) {
+	img.Clear()
+	drawOpts := &ebiten.DrawImageOptions{}
+	drawOpts.ColorM.Scale(float64(colour.R)/255, float64(colour.G)/255, float64(colour.B)/255, 1)
+	img.DrawImage(loader.LoadImage(assets.ImgBlockBtnSwatch).Data, drawOpts)
+	img.DrawImage(loader.LoadImage(frame).Data, nil)
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	CONSOLE_SCROLLBACK = 10
+	CONSOLE_HISTORY    = 50
+)
+
+type ConsoleHandlers struct {
+	Execute  func(line string) []string
+	Complete func(line string) []string
+}
+
+type Console struct {
+	window       *widget.Window
+	input        *widget.TextInput
+	scrollback   *widget.Text
+	handlers     *ConsoleHandlers
+	lines        []string
+	history      []string
+	historyIndex int
+	opening      bool
+	openText     string
+	removeWindow widget.RemoveWindowFunc
+}
+
+func newConsole(handlers *ConsoleHandlers, loader *resource.Loader) *Console {
+	console := &Console{handlers: handlers}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 4, Bottom: 4, Left: 4, Right: 4}),
+		)),
+	)
+
+	console.scrollback = widget.NewText(
+		widget.TextOpts.Text("", face, color.White),
+		widget.TextOpts.MaxWidth(450),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	container.AddChild(console.scrollback)
+
+	console.input = newTextInput(loader,
+		widget.TextInputOpts.ClearOnSubmit(true),
+		widget.TextInputOpts.IgnoreEmptySubmit(true),
+		widget.TextInputOpts.AllowDuplicateSubmit(true),
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			console.submit(args.InputText)
+		}),
+		widget.TextInputOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	container.AddChild(console.input)
+
+	console.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Location(consoleRect(config.ScreenWidth)),
+	)
+	return console
+}
+
+func (console *Console) print(lines ...string) {
+	console.lines = append(console.lines, lines...)
+	if len(console.lines) > CONSOLE_SCROLLBACK {
+		console.lines = console.lines[len(console.lines)-CONSOLE_SCROLLBACK:]
+	}
+	console.scrollback.Label = strings.Join(console.lines, "\n")
+}
+
+func (console *Console) submit(line string) {
+	console.print("> " + line)
+	console.history = append(console.history, line)
+	if len(console.history) > CONSOLE_HISTORY {
+		console.history = console.history[1:]
+	}
+	console.historyIndex = len(console.history)
+	console.print(console.handlers.Execute(line)...)
+}
+
+func (console *Console) setInput(text string) {
+	console.input.InputText = text
+	console.input.CursorMoveEnd()
+}
+
+// complete replaces the input with the only candidate, or extends it to the
+// longest prefix shared by every candidate and lists them.
+func (console *Console) complete() {
+	candidates := console.handlers.Complete(console.input.InputText)
+	if len(candidates) == 0 {
+		return
+	}
+	if len(candidates) == 1 {
+		console.setInput(candidates[0] + " ")
+		return
+	}
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	console.setInput(prefix)
+	console.print(strings.Join(candidates, "  "))
+}
+
+func (console *Console) recall(offset int) {
+	index := console.historyIndex + offset
+	if index < 0 || index > len(console.history) {
+		return
+	}
+	console.historyIndex = index
+	if index == len(console.history) {
+		console.setInput("")
+	} else {
+		console.setInput(console.history[index])
+	}
+}
+
+func (console *Console) isOpen() bool {
+	return console.removeWindow != nil
+}
+
+func (console *Console) update() {
+	if !console.isOpen() {
+		return
+	}
+	// The toggle key is typed into the input on the frame the console opens, so that one
+	// backtick is taken out again, just before the cursor where it was inserted. Any other
+	// backticks are part of the command.
+	if console.opening {
+		if text := console.input.InputText; text != console.openText {
+			console.opening = false
+			if len(text) == len(console.openText)+1 && strings.Count(text, "`") == strings.Count(console.openText, "`")+1 {
+				console.input.Backspace()
+			}
+		} else if !ebiten.IsKeyPressed(ebiten.KeyBackquote) {
+			console.opening = false
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		console.complete()
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		console.recall(-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		console.recall(1)
+	}
+}
+
+func (ui *UI) ToggleConsole() {
+	console := ui.console
+	if console.isOpen() {
+		console.input.Focus(false)
+		console.removeWindow()
+		console.removeWindow = nil
+	} else {
+		console.removeWindow = ui.ebitenUI.AddWindow(console.window)
+		console.input.Focus(true)
+		console.opening, console.openText = true, console.input.InputText
+	}
+	ui.State.ConsoleOpen = console.isOpen()
+	// Tab is used for completion while the console is open.
+	ui.ebitenUI.DisableDefaultFocus = console.isOpen()
+}
+
+func consoleRect(screenWidth int) image.Rectangle {
+	right := screenWidth - 200
+	if right < 320 {
+		right = 320
+	}
+	return image.Rect(60, 40, right, 210)
+}
@@ -1,33 +1,124 @@
 package ui
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
 type Renderer int
 
 const (
 	ISOMETRIC Renderer = iota
 	TWO_DIMENSIONAL
+	ELEVATION
+)
+
+type ElevationAxis int
+
+const (
+	ROW ElevationAxis = iota
+	COLUMN
+)
+
+func (axis ElevationAxis) String() string {
+	if axis == COLUMN {
+		return "COL"
+	}
+	return "ROW"
+}
+
+// Tool is what clicking on the board does.
+type Tool int
+
+const (
+	TOOL_BUILD Tool = iota
+	TOOL_FILL
+	TOOL_MEASURE
+	TOOL_NOTE
+	TOOL_PREFAB
+)
+
+// Overlay is a set of extra information drawn over the board.
+type Overlay int
+
+const (
+	OVERLAY_GRID Overlay = 1 << iota
+	OVERLAY_HEIGHTS
+	OVERLAY_RULERS
+	OVERLAY_HEATMAP
 )
 
+var OVERLAY_NAMES = map[string]Overlay{
+	"grid":    OVERLAY_GRID,
+	"heights": OVERLAY_HEIGHTS,
+	"rulers":  OVERLAY_RULERS,
+	"heatmap": OVERLAY_HEATMAP,
+}
+
+// BlockSize is the height of a block in quarter blocks. Stack heights use the same unit.
 type BlockSize int
 
 const (
-	HALF BlockSize = iota
-	FULL
-	FLAT
+	FLAT    BlockSize = 0
+	QUARTER BlockSize = 1
+	HALF    BlockSize = 2
+	FULL    BlockSize = 4
 )
 
+// MAX_BLOCK_SIZE is the tallest block a palette colour can have.
+const MAX_BLOCK_SIZE = 2 * FULL
+
+var BLOCK_SIZE_NAMES = map[string]BlockSize{
+	"quarter": QUARTER,
+	"half":    HALF,
+	"full":    FULL,
+}
+
 func (blockSize BlockSize) GetHeight() int {
-	switch blockSize {
-	case FULL:
-		return 2
-	case HALF:
-		return 1
-	default:
-		return 0
+	return int(blockSize)
+}
+
+// String gives the size in blocks, such as 0.25 or 1.5.
+func (blockSize BlockSize) String() string {
+	return FormatHeight(int(blockSize))
+}
+
+// FormatHeight gives a height in quarter blocks as a number of blocks.
+func FormatHeight(height int) string {
+	return strconv.FormatFloat(float64(height)/float64(FULL), 'f', -1, 64)
+}
+
+// ParseHeight reads a number of blocks in quarter steps, such as 2 or 0.75.
+func ParseHeight(s string) (int, error) {
+	blocks, err := strconv.ParseFloat(s, 64)
+	quarters := blocks * float64(FULL)
+	if err != nil || quarters != math.Trunc(quarters) || quarters < 0 {
+		return 0, fmt.Errorf("%q is not a whole number of quarter blocks", s)
+	}
+	return int(quarters), nil
+}
+
+// ParseBlockSize reads a block size by name or as a number of blocks.
+func ParseBlockSize(s string) (BlockSize, error) {
+	if size, ok := BLOCK_SIZE_NAMES[strings.ToLower(s)]; ok {
+		return size, nil
+	}
+	height, err := ParseHeight(s)
+	if err != nil {
+		return FLAT, err
+	}
+	if size := BlockSize(height); size >= QUARTER && size <= MAX_BLOCK_SIZE {
+		return size, nil
 	}
+	return FLAT, fmt.Errorf("block size %s is not between %s and %s", s, QUARTER, MAX_BLOCK_SIZE)
 }
 
+// BlockOperation is SELECT or the ID of a colour in the palette.
 type BlockOperation int
 
+// The IDs of the colours in the default palette.
 const (
 	SELECT BlockOperation = iota
 	PLACE_BLUE
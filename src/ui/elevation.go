@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+type ElevationControls struct {
+	container  *widget.Container
+	axisButton *widget.Button
+	prevButton *widget.Button
+	nextButton *widget.Button
+	indexText  *widget.Text
+}
+
+func newElevationControls(state *State, loader *resource.Loader) *ElevationControls {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(2),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 2}),
+		)),
+	)
+
+	var axisClicked widget.ButtonClickedHandlerFunc = func(args *widget.ButtonClickedEventArgs) {
+		if state.ElevationAxis == ROW {
+			state.ElevationAxis = COLUMN
+		} else {
+			state.ElevationAxis = ROW
+		}
+	}
+	axisButton := newTextButton(ROW.String(), loader, widget.ButtonOpts.ClickedHandler(axisClicked))
+	container.AddChild(axisButton)
+
+	var prevClicked widget.ButtonClickedHandlerFunc = func(args *widget.ButtonClickedEventArgs) {
+		state.ElevationIndex--
+	}
+	prevButton := newTextButton("<", loader, widget.ButtonOpts.ClickedHandler(prevClicked))
+	container.AddChild(prevButton)
+
+	indexText := widget.NewText(
+		widget.TextOpts.Text("00", loader.LoadFont(assets.FontDefault).Face, color.White),
+		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	container.AddChild(indexText)
+
+	var nextClicked widget.ButtonClickedHandlerFunc = func(args *widget.ButtonClickedEventArgs) {
+		state.ElevationIndex++
+	}
+	nextButton := newTextButton(">", loader, widget.ButtonOpts.ClickedHandler(nextClicked))
+	container.AddChild(nextButton)
+
+	return &ElevationControls{
+		container:  container,
+		axisButton: axisButton,
+		prevButton: prevButton,
+		nextButton: nextButton,
+		indexText:  indexText,
+	}
+}
+
+func (controls *ElevationControls) update(state *State) {
+	disabled := state.Renderer != ELEVATION
+	controls.axisButton.GetWidget().Disabled = disabled
+	controls.prevButton.GetWidget().Disabled = disabled
+	controls.nextButton.GetWidget().Disabled = disabled
+
+	controls.axisButton.Text().Label = state.ElevationAxis.String()
+	controls.indexText.Label = fmt.Sprintf("%02d", state.ElevationIndex)
+	if disabled {
+		controls.indexText.Color = color.RGBA{R: 87, G: 114, B: 119, A: 255} // #577277
+	} else {
+		controls.indexText.Color = color.White
+	}
+}
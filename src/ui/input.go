@@ -7,11 +7,33 @@ import (
 const (
 	ActionSelect input.Action = iota
 	ActionDelete
+	ActionPanUp
+	ActionPanDown
+	ActionPanLeft
+	ActionPanRight
+	ActionLayerUp
+	ActionLayerDown
+	ActionUndo
+	ActionToggleConsole
+	ActionToggleFullscreen
+	ActionRotatePrefab
+	ActionMirrorPrefab
 )
 
 func NewKeyMap() input.Keymap {
 	return input.Keymap{
-		ActionSelect: {input.KeyMouseLeft},
-		ActionDelete: {input.KeyMouseRight},
+		ActionSelect:           {input.KeyMouseLeft},
+		ActionDelete:           {input.KeyMouseRight},
+		ActionPanUp:            {input.KeyUp},
+		ActionPanDown:          {input.KeyDown},
+		ActionPanLeft:          {input.KeyLeft},
+		ActionPanRight:         {input.KeyRight},
+		ActionLayerUp:          {input.KeyPageUp},
+		ActionLayerDown:        {input.KeyPageDown},
+		ActionUndo:             {input.KeyWithModifier(input.KeyZ, input.ModControl)},
+		ActionToggleConsole:    {input.KeyBackquote},
+		ActionToggleFullscreen: {input.KeyWithModifier(input.KeyF, input.ModControl)},
+		ActionRotatePrefab:     {input.KeyR},
+		ActionMirrorPrefab:     {input.KeyM},
 	}
 }
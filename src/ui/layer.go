@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+type LayerControls struct {
+	container *widget.Container
+	layerText *widget.Text
+}
+
+func newLayerControls(state *State, loader *resource.Loader) *LayerControls {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(2),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 8}),
+		)),
+	)
+
+	var downClicked widget.ButtonClickedHandlerFunc = func(args *widget.ButtonClickedEventArgs) {
+		state.LayerHeight--
+	}
+	container.AddChild(newTextButton("-", loader, widget.ButtonOpts.ClickedHandler(downClicked)))
+
+	layerText := widget.NewText(
+		widget.TextOpts.Text("LAYER 0.00", loader.LoadFont(assets.FontDefault).Face, color.White),
+		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	container.AddChild(layerText)
+
+	var upClicked widget.ButtonClickedHandlerFunc = func(args *widget.ButtonClickedEventArgs) {
+		state.LayerHeight++
+	}
+	container.AddChild(newTextButton("+", loader, widget.ButtonOpts.ClickedHandler(upClicked)))
+
+	return &LayerControls{
+		container: container,
+		layerText: layerText,
+	}
+}
+
+func (controls *LayerControls) update(state *State) {
+	controls.layerText.Label = "LAYER " + FormatHeight(state.LayerHeight)
+}
@@ -0,0 +1,18 @@
+package ui
+
+// SetScreenSize updates the logical screen size that windows are positioned against.
+func (ui *UI) SetScreenSize(w int, h int) {
+	ui.screenWidth = w
+	ui.screenHeight = h
+}
+
+// layoutWindows keeps windows anchored to the screen edges as it and the board resize.
+func (ui *UI) layoutWindows() {
+	ui.minimapWindow.SetLocation(minimapRect(ui.Minimap, ui.screenWidth))
+	ui.Notifications.window.SetLocation(notificationsRect(ui.screenWidth))
+	ui.console.window.SetLocation(consoleRect(ui.screenWidth))
+	ui.settingsMenu.window.SetLocation(settingsRect(ui.screenWidth, ui.screenHeight))
+	ui.paletteEditor.window.SetLocation(paletteEditorRect(ui.screenWidth, ui.screenHeight))
+	ui.noteEditor.window.SetLocation(noteEditorRect(ui.screenWidth, ui.screenHeight))
+	ui.statsPanel.window.SetLocation(statsRect(ui.screenWidth, ui.screenHeight))
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	MINIMAP_CELL_SIZE = 6
+	MINIMAP_PADDING   = 3
+)
+
+type MinimapPoint struct {
+	X float64
+	Y float64
+}
+
+type MinimapSource interface {
+	Dimensions() (int, int)
+	MaxHeight() int
+	TopBlock(x int, y int) (BlockOperation, int)
+	HoveredStack() (int, int, bool)
+	Viewport(state *State) [4]MinimapPoint
+	JumpTo(state *State, x float64, y float64)
+}
+
+type Minimap struct {
+	widget *widget.Widget
+	source MinimapSource
+	state  *State
+}
+
+func newMinimap(source MinimapSource, state *State) *Minimap {
+	minimap := &Minimap{
+		source: source,
+		state:  state,
+	}
+	minimap.widget = widget.NewWidget()
+	minimap.widget.MouseButtonPressedEvent.AddHandler(func(args interface{}) {
+		pressedArgs := args.(*widget.WidgetMouseButtonPressedEventArgs)
+		if pressedArgs.Button != ebiten.MouseButtonLeft {
+			return
+		}
+		x := float64(pressedArgs.OffsetX-MINIMAP_PADDING) / MINIMAP_CELL_SIZE
+		y := float64(pressedArgs.OffsetY-MINIMAP_PADDING) / MINIMAP_CELL_SIZE
+		minimap.source.JumpTo(minimap.state, x, y)
+	})
+	return minimap
+}
+
+func newMinimapWindow(minimap *Minimap) *widget.Window {
+	container := widget.NewContainer(widget.ContainerOpts.Layout(widget.NewAnchorLayout()))
+	container.AddChild(minimap)
+
+	return widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Location(minimapRect(minimap, config.ScreenWidth)),
+	)
+}
+
+// minimapRect pins the minimap to the top right corner. It grows with the board.
+func minimapRect(minimap *Minimap, screenWidth int) image.Rectangle {
+	w, h := minimap.PreferredSize()
+	x := screenWidth - w - 5
+	return image.Rect(x, 5, x+w, 5+h)
+}
+
+func (m *Minimap) GetWidget() *widget.Widget {
+	return m.widget
+}
+
+func (m *Minimap) SetLocation(rect image.Rectangle) {
+	m.widget.Rect = rect
+}
+
+func (m *Minimap) PreferredSize() (int, int) {
+	w, h := m.source.Dimensions()
+	return w*MINIMAP_CELL_SIZE + MINIMAP_PADDING*2, h*MINIMAP_CELL_SIZE + MINIMAP_PADDING*2
+}
+
+func containsCursor(window *widget.Window) bool {
+	x, y := ebiten.CursorPosition()
+	return image.Pt(x, y).In(window.GetContainer().GetWidget().Rect)
+}
+
+func (m *Minimap) Render(screen *ebiten.Image, def widget.DeferredRenderFunc) {
+	m.widget.Render(screen, def)
+	m.draw(screen)
+}
+
+func (m *Minimap) draw(screen *ebiten.Image) {
+	rect := m.widget.Rect
+	screen = screen.SubImage(rect).(*ebiten.Image)
+	vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), color.RGBA{R: 9, G: 10, B: 20, A: 255}, false)
+
+	originX := float32(rect.Min.X + MINIMAP_PADDING)
+	originY := float32(rect.Min.Y + MINIMAP_PADDING)
+	w, h := m.source.Dimensions()
+	maxHeight := m.source.MaxHeight()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			blockOperation, height := m.source.TopBlock(x, y)
+			vector.DrawFilledRect(screen,
+				originX+float32(x*MINIMAP_CELL_SIZE), originY+float32(y*MINIMAP_CELL_SIZE),
+				MINIMAP_CELL_SIZE, MINIMAP_CELL_SIZE,
+				shadeByHeight(m.state.Palette.Colour(blockOperation), height, maxHeight), false)
+		}
+	}
+
+	if x, y, ok := m.source.HoveredStack(); ok {
+		vector.StrokeRect(screen,
+			originX+float32(x*MINIMAP_CELL_SIZE), originY+float32(y*MINIMAP_CELL_SIZE),
+			MINIMAP_CELL_SIZE, MINIMAP_CELL_SIZE, 1, color.White, false)
+	}
+
+	viewport := m.source.Viewport(m.state)
+	for i := range viewport {
+		from := viewport[i]
+		to := viewport[(i+1)%len(viewport)]
+		vector.StrokeLine(screen,
+			originX+float32(from.X*MINIMAP_CELL_SIZE), originY+float32(from.Y*MINIMAP_CELL_SIZE),
+			originX+float32(to.X*MINIMAP_CELL_SIZE), originY+float32(to.Y*MINIMAP_CELL_SIZE),
+			1, color.RGBA{R: 235, G: 237, B: 233, A: 255}, false)
+	}
+}
+
+func shadeByHeight(c color.RGBA, height int, maxHeight int) color.RGBA {
+	shade := 0.4
+	if maxHeight > 0 {
+		shade += 0.6 * float64(height) / float64(maxHeight)
+	}
+	return color.RGBA{
+		R: uint8(float64(c.R) * shade),
+		G: uint8(float64(c.G) * shade),
+		B: uint8(float64(c.B) * shade),
+		A: c.A,
+	}
+}
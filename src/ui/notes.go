@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	NOTE_EDITOR_WIDTH  = 260
+	NOTE_EDITOR_HEIGHT = 80
+)
+
+// NoteHandlers stores the text of a note. Saving empty text removes the note.
+type NoteHandlers struct {
+	Save func(x int, y int, text string)
+}
+
+type noteRequest struct {
+	x    int
+	y    int
+	text string
+}
+
+// EditNote opens the note editor for the cell at x, y, starting from text.
+func (state *State) EditNote(x int, y int, text string) {
+	state.noteRequest = &noteRequest{x: x, y: y, text: text}
+}
+
+type NoteEditor struct {
+	window       *widget.Window
+	title        *widget.Text
+	input        *widget.TextInput
+	handlers     *NoteHandlers
+	x            int
+	y            int
+	removeWindow widget.RemoveWindowFunc
+	ui           *UI
+}
+
+func newNoteEditor(handlers *NoteHandlers, loader *resource.Loader) *NoteEditor {
+	editor := &NoteEditor{handlers: handlers}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 6, Bottom: 6, Left: 6, Right: 6}),
+		)),
+	)
+	stretch := widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})
+
+	editor.title = widget.NewText(
+		widget.TextOpts.Text("NOTE", face, color.White),
+		widget.TextOpts.WidgetOpts(stretch),
+	)
+	container.AddChild(editor.title)
+
+	editor.input = newTextInput(loader,
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			editor.save(args.InputText)
+		}),
+		widget.TextInputOpts.WidgetOpts(stretch),
+	)
+	container.AddChild(editor.input)
+
+	buttons := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(2))),
+	)
+	buttons.AddChild(newTextButton("SAVE", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		editor.save(editor.input.InputText)
+	})))
+	buttons.AddChild(newTextButton("DELETE", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		editor.save("")
+	})))
+	buttons.AddChild(newTextButton("CANCEL", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		editor.ui.closeNoteEditor()
+	})))
+	container.AddChild(buttons)
+
+	editor.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Modal(),
+		widget.WindowOpts.Location(noteEditorRect(config.ScreenWidth, config.ScreenHeight)),
+	)
+	return editor
+}
+
+func (editor *NoteEditor) isOpen() bool {
+	return editor.removeWindow != nil
+}
+
+func (editor *NoteEditor) save(text string) {
+	editor.handlers.Save(editor.x, editor.y, strings.TrimSpace(text))
+	editor.ui.closeNoteEditor()
+}
+
+// updateNoteEditor opens the editor for the note most recently asked for with EditNote.
+func (ui *UI) updateNoteEditor() {
+	request := ui.State.noteRequest
+	if request == nil {
+		return
+	}
+	ui.State.noteRequest = nil
+	editor := ui.noteEditor
+	editor.x, editor.y = request.x, request.y
+	editor.title.Label = fmt.Sprintf("NOTE %d,%d", request.x, request.y)
+	editor.input.InputText = request.text
+	editor.input.CursorMoveEnd()
+	if !editor.isOpen() {
+		editor.ui = ui
+		editor.removeWindow = ui.ebitenUI.AddWindow(editor.window)
+	}
+	editor.input.Focus(true)
+	ui.State.NoteOpen = true
+}
+
+func (ui *UI) closeNoteEditor() {
+	editor := ui.noteEditor
+	if editor.isOpen() {
+		editor.input.Focus(false)
+		editor.removeWindow()
+		editor.removeWindow = nil
+	}
+	ui.State.NoteOpen = false
+}
+
+func noteEditorRect(screenWidth int, screenHeight int) image.Rectangle {
+	x := (screenWidth - NOTE_EDITOR_WIDTH) / 2
+	y := (screenHeight - NOTE_EDITOR_HEIGHT) / 2
+	return image.Rect(x, y, x+NOTE_EDITOR_WIDTH, y+NOTE_EDITOR_HEIGHT)
+}
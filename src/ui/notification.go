@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+type NotificationKind int
+
+const (
+	INFO NotificationKind = iota
+	WARNING
+	ERROR
+)
+
+const (
+	NOTIFICATION_TICKS        = 150
+	NOTIFICATION_BLINK_TICKS  = 60
+	NOTIFICATION_ANIMATE_TICK = 20
+	MAX_VISIBLE_NOTIFICATIONS = 3
+	MAX_QUEUED_NOTIFICATIONS  = 20
+)
+
+func (kind NotificationKind) colour() color.RGBA {
+	switch kind {
+	case WARNING:
+		return color.RGBA{R: 232, G: 193, B: 112, A: 255} // #e8c170
+	case ERROR:
+		return color.RGBA{R: 207, G: 87, B: 60, A: 255} // #cf573c
+	default:
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+}
+
+func (kind NotificationKind) icon() resource.ImageID {
+	switch kind {
+	case WARNING:
+		return assets.ImgIconWarning
+	case ERROR:
+		return assets.ImgIconError
+	default:
+		return assets.ImgIconInfo
+	}
+}
+
+// Notification is a toast message. A zero Icon uses the kind's icon and zero Ticks
+// uses NOTIFICATION_TICKS.
+type Notification struct {
+	Kind  NotificationKind
+	Text  string
+	Icon  resource.ImageID
+	Ticks int
+}
+
+func (state *State) Notify(notification Notification) {
+	state.notifications = append(state.notifications, notification)
+}
+
+func (state *State) Info(text string) {
+	state.Notify(Notification{Kind: INFO, Text: text})
+}
+
+func (state *State) Warn(text string) {
+	state.Notify(Notification{Kind: WARNING, Text: text})
+}
+
+func (state *State) Error(text string) {
+	state.Notify(Notification{Kind: ERROR, Text: text})
+}
+
+type toast struct {
+	notification Notification
+	tick         int
+	text         *widget.Text
+	container    *widget.Container
+}
+
+func (t *toast) duration() int {
+	if t.notification.Ticks > 0 {
+		return t.notification.Ticks
+	}
+	return NOTIFICATION_TICKS
+}
+
+type Notifications struct {
+	window    *widget.Window
+	container *widget.Container
+	loader    *resource.Loader
+	visible   []*toast
+	queue     []Notification
+}
+
+func newNotifications(loader *resource.Loader) *Notifications {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(2),
+		)),
+	)
+	window := widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Location(notificationsRect(config.ScreenWidth)),
+	)
+	return &Notifications{
+		window:    window,
+		container: container,
+		loader:    loader,
+	}
+}
+
+func notificationsRect(screenWidth int) image.Rectangle {
+	return image.Rect(screenWidth/2-130, 28, screenWidth/2+130, 28+MAX_VISIBLE_NOTIFICATIONS*22)
+}
+
+// push queues a notification unless the same message is already showing, in which case
+// that toast starts over.
+func (notifications *Notifications) push(notification Notification) {
+	for _, t := range notifications.visible {
+		if t.notification.Kind == notification.Kind && t.notification.Text == notification.Text {
+			t.tick = 0
+			return
+		}
+	}
+	if len(notifications.queue) < MAX_QUEUED_NOTIFICATIONS {
+		notifications.queue = append(notifications.queue, notification)
+	}
+}
+
+func (notifications *Notifications) newToast(notification Notification) *toast {
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 220})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 3, Bottom: 3, Left: 4, Right: 4}),
+		)),
+	)
+	icon := notification.Icon
+	if icon == assets.ImgNone {
+		icon = notification.Kind.icon()
+	}
+	container.AddChild(widget.NewGraphic(
+		widget.GraphicOpts.Image(notifications.loader.LoadImage(icon).Data),
+		widget.GraphicOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter})),
+	))
+	text := widget.NewText(
+		widget.TextOpts.Text(notification.Text, notifications.loader.LoadFont(assets.FontDefault).Face, notification.Kind.colour()),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter})),
+	)
+	container.AddChild(text)
+	return &toast{notification: notification, text: text, container: container}
+}
+
+func (notifications *Notifications) update(state *State) {
+	for _, notification := range state.notifications {
+		if notification.Kind != INFO {
+			state.PlaySound(assets.AudioAlert)
+		}
+		notifications.push(notification)
+	}
+	state.notifications = state.notifications[:0]
+
+	changed := false
+	visible := notifications.visible[:0]
+	for _, t := range notifications.visible {
+		t.tick++
+		if t.tick < t.duration() {
+			visible = append(visible, t)
+		} else {
+			changed = true
+		}
+	}
+	notifications.visible = visible
+	for len(notifications.visible) < MAX_VISIBLE_NOTIFICATIONS && len(notifications.queue) > 0 {
+		notifications.visible = append(notifications.visible, notifications.newToast(notifications.queue[0]))
+		notifications.queue = notifications.queue[1:]
+		changed = true
+	}
+
+	if changed {
+		notifications.container.RemoveChildren()
+		for _, t := range notifications.visible {
+			notifications.container.AddChild(t.container)
+		}
+	}
+
+	// Warnings and errors blink when they first appear.
+	for _, t := range notifications.visible {
+		t.text.Color = t.notification.Kind.colour()
+		if t.notification.Kind != INFO && t.tick < NOTIFICATION_BLINK_TICKS && (t.tick/NOTIFICATION_ANIMATE_TICK)%2 == 1 {
+			t.text.Color = color.Transparent
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+)
+
+type OverlayToggles struct {
+	container *widget.Container
+	toggles   map[Overlay]*widget.Button
+}
+
+func newOverlayToggles(state *State, loader *resource.Loader) *OverlayToggles {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(2))),
+	)
+
+	overlays := []struct {
+		label   string
+		overlay Overlay
+	}{
+		{label: "GRID", overlay: OVERLAY_GRID},
+		{label: "HGT", overlay: OVERLAY_HEIGHTS},
+		{label: "RUL", overlay: OVERLAY_RULERS},
+		{label: "HEAT", overlay: OVERLAY_HEATMAP},
+	}
+
+	toggles := map[Overlay]*widget.Button{}
+	for _, o := range overlays {
+		overlay := o.overlay
+		var toggled widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+			if args.State == widget.WidgetChecked {
+				state.Overlays |= overlay
+			} else {
+				state.Overlays &^= overlay
+			}
+		}
+		toggle := newTextButton(o.label, loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.StateChangedHandler(toggled),
+		)
+		toggles[overlay] = toggle
+		container.AddChild(toggle)
+	}
+
+	return &OverlayToggles{
+		container: container,
+		toggles:   toggles,
+	}
+}
+
+func (toggles *OverlayToggles) update(state *State) {
+	for overlay, toggle := range toggles.toggles {
+		syncToggle(toggle, state.Overlays&overlay != 0)
+	}
+}
@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"image/color"
+	"sort"
+	"strings"
+)
+
+const (
+	MAX_PALETTE_COLOURS = 12
+	// MAX_COLOUR_ID is the highest ID a colour can have, so IDs fit in a byte.
+	MAX_COLOUR_ID          = 255
+	MAX_COLOUR_NAME_LENGTH = 32
+)
+
+var SELECT_COLOUR = color.RGBA{R: 129, G: 151, B: 150, A: 255} // #819796
+
+// DEFAULT_SIZES are the block sizes of a colour that does not declare any.
+var DEFAULT_SIZES = []BlockSize{HALF, FULL}
+
+// PaletteColour is a block type. Sizes are the heights it can be placed at, shortest first.
+type PaletteColour struct {
+	ID     BlockOperation
+	Name   string
+	Colour color.RGBA
+	Sizes  []BlockSize
+}
+
+// Palette is the ordered list of colours blocks can be placed in. IDs are stable,
+// so reordering or recolouring never changes which colour a placed block refers to.
+type Palette struct {
+	colours []PaletteColour
+	version int
+}
+
+func DefaultPalette() *Palette {
+	return NewPalette([]PaletteColour{
+		{ID: PLACE_BLUE, Name: "blue", Colour: color.RGBA{R: 115, G: 190, B: 211, A: 255}, Sizes: []BlockSize{QUARTER, HALF, FULL}}, // #73bed3
+		{ID: PLACE_RED, Name: "red", Colour: color.RGBA{R: 207, G: 87, B: 60, A: 255}, Sizes: []BlockSize{HALF, FULL, FULL + HALF}}, // #cf573c
+		{ID: PLACE_YELLOW, Name: "yellow", Colour: color.RGBA{R: 232, G: 193, B: 112, A: 255}, Sizes: DEFAULT_SIZES},                // #e8c170
+	})
+}
+
+func NewPalette(colours []PaletteColour) *Palette {
+	palette := &Palette{}
+	palette.Replace(colours)
+	return palette
+}
+
+// Version changes whenever the palette does, so views can tell when to rebuild.
+func (palette *Palette) Version() int {
+	return palette.version
+}
+
+func (palette *Palette) Colours() []PaletteColour {
+	return palette.colours
+}
+
+func (palette *Palette) Replace(colours []PaletteColour) {
+	palette.colours = append([]PaletteColour(nil), colours...)
+	palette.version++
+}
+
+func (palette *Palette) index(id BlockOperation) int {
+	for i, colour := range palette.colours {
+		if colour.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (palette *Palette) Has(id BlockOperation) bool {
+	return palette.index(id) >= 0
+}
+
+// Colour returns the colour for id, or the grey of an empty tile for SELECT and unknown IDs.
+func (palette *Palette) Colour(id BlockOperation) color.RGBA {
+	if i := palette.index(id); i >= 0 {
+		return palette.colours[i].Colour
+	}
+	return SELECT_COLOUR
+}
+
+func (palette *Palette) Name(id BlockOperation) string {
+	if i := palette.index(id); i >= 0 {
+		return palette.colours[i].Name
+	}
+	return ""
+}
+
+// Lookup finds a colour by name, ignoring case.
+func (palette *Palette) Lookup(name string) (BlockOperation, bool) {
+	for _, colour := range palette.colours {
+		if strings.EqualFold(colour.Name, name) {
+			return colour.ID, true
+		}
+	}
+	return SELECT, false
+}
+
+// Add appends a colour with a new ID, returning SELECT if the palette is full or out of IDs.
+func (palette *Palette) Add(name string, c color.RGBA) BlockOperation {
+	if len(palette.colours) >= MAX_PALETTE_COLOURS {
+		return SELECT
+	}
+	id := SELECT
+	for _, colour := range palette.colours {
+		if colour.ID > id {
+			id = colour.ID
+		}
+	}
+	id++
+	if id > MAX_COLOUR_ID {
+		return SELECT
+	}
+	palette.colours = append(palette.colours, PaletteColour{ID: id, Name: name, Colour: c, Sizes: DEFAULT_SIZES})
+	palette.version++
+	return id
+}
+
+// Rename changes the name of a colour, cut to MAX_COLOUR_NAME_LENGTH bytes.
+func (palette *Palette) Rename(id BlockOperation, name string) {
+	if len(name) > MAX_COLOUR_NAME_LENGTH {
+		name = strings.ToValidUTF8(name[:MAX_COLOUR_NAME_LENGTH], "")
+	}
+	if i := palette.index(id); i >= 0 && palette.colours[i].Name != name {
+		palette.colours[i].Name = name
+		palette.version++
+	}
+}
+
+func (palette *Palette) SetColour(id BlockOperation, c color.RGBA) {
+	if i := palette.index(id); i >= 0 && palette.colours[i].Colour != c {
+		palette.colours[i].Colour = c
+		palette.version++
+	}
+}
+
+// Sizes returns the block sizes of a colour, or nil for SELECT and unknown IDs.
+func (palette *Palette) Sizes(id BlockOperation) []BlockSize {
+	if i := palette.index(id); i >= 0 {
+		if len(palette.colours[i].Sizes) == 0 {
+			return DEFAULT_SIZES
+		}
+		return palette.colours[i].Sizes
+	}
+	return nil
+}
+
+func (palette *Palette) HasSize(id BlockOperation, size BlockSize) bool {
+	for _, available := range palette.Sizes(id) {
+		if available == size {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSizes replaces the block sizes of a colour, sorting them and dropping repeats.
+func (palette *Palette) SetSizes(id BlockOperation, sizes []BlockSize) {
+	i := palette.index(id)
+	if i < 0 || len(sizes) == 0 {
+		return
+	}
+	sorted := append([]BlockSize(nil), sizes...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	unique := sorted[:1]
+	for _, size := range sorted[1:] {
+		if size != unique[len(unique)-1] {
+			unique = append(unique, size)
+		}
+	}
+	palette.colours[i].Sizes = unique
+	palette.version++
+}
+
+// NearestSize picks the size of a colour closest to size, preferring the taller of two.
+func (palette *Palette) NearestSize(id BlockOperation, size BlockSize) BlockSize {
+	nearest := size
+	for i, available := range palette.Sizes(id) {
+		if i == 0 || abs(int(available-size)) <= abs(int(nearest-size)) {
+			nearest = available
+		}
+	}
+	return nearest
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Move shifts a colour offset places along the palette.
+func (palette *Palette) Move(id BlockOperation, offset int) {
+	i := palette.index(id)
+	j := i + offset
+	if i < 0 || j < 0 || j >= len(palette.colours) {
+		return
+	}
+	colour := palette.colours[i]
+	palette.colours = append(palette.colours[:i], palette.colours[i+1:]...)
+	palette.colours = append(palette.colours[:j], append([]PaletteColour{colour}, palette.colours[j:]...)...)
+	palette.version++
+}
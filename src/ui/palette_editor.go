@@ -0,0 +1,346 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	PALETTE_EDITOR_WIDTH  = 260
+	PALETTE_EDITOR_HEIGHT = 220
+)
+
+var (
+	RGB_CHANNELS = [3]string{"R", "G", "B"}
+	HSV_CHANNELS = [3]string{"H", "S", "V"}
+	RGB_MAXIMUMS = [3]int{255, 255, 255}
+	HSV_MAXIMUMS = [3]int{359, 100, 100}
+)
+
+// PaletteEditor adds, renames, reorders and recolours the colours in State.Palette.
+type PaletteEditor struct {
+	window       *widget.Window
+	list         *widget.Container
+	listButtons  []*widget.Button
+	listIDs      []BlockOperation
+	listNames    []string
+	nameInput    *widget.TextInput
+	preview      *ebiten.Image
+	modeButton   *widget.Button
+	sliders      [3]*widget.Slider
+	sliderLabels [3]*widget.Text
+	hsv          bool
+	synced       [3]int
+	selected     BlockOperation
+	version      int
+	loader       *resource.Loader
+	removeWindow widget.RemoveWindowFunc
+	ui           *UI
+}
+
+func newPaletteEditor(state *State, loader *resource.Loader) *PaletteEditor {
+	editor := &PaletteEditor{loader: loader, version: -1}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 6, Bottom: 6, Left: 6, Right: 6}),
+		)),
+	)
+	stretch := widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})
+
+	editor.list = widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(4),
+			widget.GridLayoutOpts.Stretch([]bool{true, true, true, true}, nil),
+			widget.GridLayoutOpts.Spacing(2, 2),
+		)),
+		widget.ContainerOpts.WidgetOpts(stretch),
+	)
+	container.AddChild(editor.list)
+
+	nameRow := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(4))),
+		widget.ContainerOpts.WidgetOpts(stretch),
+	)
+	swatch := loader.LoadImage(assets.ImgBlockBtnSwatch).Data
+	editor.preview = ebiten.NewImage(swatch.Bounds().Dx(), swatch.Bounds().Dy())
+	nameRow.AddChild(widget.NewGraphic(widget.GraphicOpts.Image(editor.preview)))
+	editor.nameInput = newTextInput(loader,
+		widget.TextInputOpts.ChangedHandler(func(args *widget.TextInputChangedEventArgs) {
+			if args.InputText != "" {
+				state.Palette.Rename(editor.selected, args.InputText)
+			}
+		}),
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(180, 0),
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter}),
+		),
+	)
+	nameRow.AddChild(editor.nameInput)
+	container.AddChild(nameRow)
+
+	sliderGrid := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+			widget.GridLayoutOpts.Spacing(8, 4),
+		)),
+		widget.ContainerOpts.WidgetOpts(stretch),
+	)
+	for i := range editor.sliders {
+		editor.sliderLabels[i] = widget.NewText(
+			widget.TextOpts.Text(RGB_CHANNELS[i], face, color.White),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		)
+		sliderGrid.AddChild(editor.sliderLabels[i])
+		editor.sliders[i] = newSlider(0, RGB_MAXIMUMS[i], func(args *widget.SliderChangedEventArgs) {
+			editor.applySliders(state)
+		}, loader)
+		sliderGrid.AddChild(editor.sliders[i])
+	}
+	container.AddChild(sliderGrid)
+
+	buttons := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(2))),
+	)
+	editor.modeButton = newTextButton("HSV", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		editor.setMode(!editor.hsv)
+	}))
+	buttons.AddChild(editor.modeButton)
+	buttons.AddChild(newTextButton("ADD", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		name := fmt.Sprintf("colour%d", len(state.Palette.Colours())+1)
+		id := state.Palette.Add(name, state.Palette.Colour(editor.selected))
+		if id == SELECT {
+			state.Warn(fmt.Sprintf("The palette is limited to %d colours", MAX_PALETTE_COLOURS))
+			return
+		}
+		editor.selected = id
+	})))
+	buttons.AddChild(newTextButton("LEFT", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		state.Palette.Move(editor.selected, -1)
+	})))
+	buttons.AddChild(newTextButton("RIGHT", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		state.Palette.Move(editor.selected, 1)
+	})))
+	buttons.AddChild(newTextButton("CLOSE", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		editor.ui.TogglePalette()
+	})))
+	container.AddChild(buttons)
+
+	editor.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Modal(),
+		widget.WindowOpts.Location(paletteEditorRect(config.ScreenWidth, config.ScreenHeight)),
+	)
+	return editor
+}
+
+func (editor *PaletteEditor) isOpen() bool {
+	return editor.removeWindow != nil
+}
+
+func (editor *PaletteEditor) choose(state *State, id BlockOperation) {
+	editor.selected = id
+	editor.nameInput.InputText = state.Palette.Name(id)
+	editor.nameInput.CursorMoveEnd()
+	editor.version = -1
+}
+
+func (editor *PaletteEditor) setMode(hsv bool) {
+	editor.hsv = hsv
+	channels, maximums, label := RGB_CHANNELS, RGB_MAXIMUMS, "HSV"
+	if hsv {
+		channels, maximums, label = HSV_CHANNELS, HSV_MAXIMUMS, "RGB"
+	}
+	for i, slider := range editor.sliders {
+		editor.sliderLabels[i].Label = channels[i]
+		slider.Max = maximums[i]
+	}
+	editor.modeButton.Text().Label = label
+	editor.version = -1
+}
+
+// applySliders recolours the selected colour from the slider positions.
+func (editor *PaletteEditor) applySliders(state *State) {
+	values := [3]int{}
+	for i, slider := range editor.sliders {
+		values[i] = slider.Current
+	}
+	// Sliders also report the positions they were moved to by syncSliders.
+	if values == editor.synced {
+		return
+	}
+	editor.synced = values
+	c := color.RGBA{R: uint8(values[0]), G: uint8(values[1]), B: uint8(values[2]), A: 255}
+	if editor.hsv {
+		c = hsvToRGB(values[0], values[1], values[2])
+	}
+	state.Palette.SetColour(editor.selected, c)
+	editor.version = state.Palette.Version()
+}
+
+// syncSliders moves the sliders to the selected colour. It is skipped while the
+// sliders are the source of the change, so HSV values do not drift through RGB.
+func (editor *PaletteEditor) syncSliders(state *State) {
+	c := state.Palette.Colour(editor.selected)
+	values := [3]int{int(c.R), int(c.G), int(c.B)}
+	if editor.hsv {
+		values[0], values[1], values[2] = rgbToHSV(c)
+	}
+	for i, slider := range editor.sliders {
+		slider.Current = values[i]
+	}
+	editor.synced = values
+	editor.version = state.Palette.Version()
+}
+
+func (editor *PaletteEditor) rebuildList(state *State) {
+	editor.list.RemoveChildren()
+	editor.listButtons = editor.listButtons[:0]
+	editor.listIDs = editor.listIDs[:0]
+	editor.listNames = editor.listNames[:0]
+	for _, colour := range state.Palette.Colours() {
+		id := colour.ID
+		button := newTextButton(colour.Name, editor.loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				editor.choose(state, id)
+			}),
+		)
+		editor.list.AddChild(button)
+		editor.listButtons = append(editor.listButtons, button)
+		editor.listIDs = append(editor.listIDs, id)
+		editor.listNames = append(editor.listNames, colour.Name)
+	}
+}
+
+func (editor *PaletteEditor) listChanged(state *State) bool {
+	colours := state.Palette.Colours()
+	if len(colours) != len(editor.listIDs) {
+		return true
+	}
+	for i, colour := range colours {
+		if colour.ID != editor.listIDs[i] || colour.Name != editor.listNames[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (editor *PaletteEditor) update(state *State) {
+	if !editor.isOpen() {
+		return
+	}
+	if !state.Palette.Has(editor.selected) && len(state.Palette.Colours()) > 0 {
+		editor.choose(state, state.Palette.Colours()[0].ID)
+	}
+	if editor.listChanged(state) {
+		editor.rebuildList(state)
+	}
+	for i, button := range editor.listButtons {
+		syncToggle(button, editor.listIDs[i] == editor.selected)
+	}
+	if editor.version != state.Palette.Version() {
+		editor.syncSliders(state)
+	}
+
+	c := state.Palette.Colour(editor.selected)
+	editor.preview.Clear()
+	drawOpts := &ebiten.DrawImageOptions{}
+	drawOpts.ColorM.Scale(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255, 1)
+	editor.preview.DrawImage(editor.loader.LoadImage(assets.ImgBlockBtnSwatch).Data, drawOpts)
+}
+
+// TogglePalette opens or closes the palette editor. It stays shut during a shared session,
+// as palette changes are neither sent to other players nor recorded as edits.
+func (ui *UI) TogglePalette() {
+	editor := ui.paletteEditor
+	if !editor.isOpen() && ui.State.SharedSession {
+		ui.State.Warn("The palette cannot be edited during a shared session")
+		return
+	}
+	if editor.isOpen() {
+		editor.nameInput.Focus(false)
+		editor.removeWindow()
+		editor.removeWindow = nil
+	} else {
+		editor.ui = ui
+		editor.removeWindow = ui.ebitenUI.AddWindow(editor.window)
+		if *ui.State.BlockOperation != SELECT {
+			editor.choose(ui.State, *ui.State.BlockOperation)
+		} else {
+			editor.choose(ui.State, editor.selected)
+		}
+	}
+	ui.State.PaletteOpen = editor.isOpen()
+}
+
+func paletteEditorRect(screenWidth int, screenHeight int) image.Rectangle {
+	x := (screenWidth - PALETTE_EDITOR_WIDTH) / 2
+	y := (screenHeight - PALETTE_EDITOR_HEIGHT) / 2
+	return image.Rect(x, y, x+PALETTE_EDITOR_WIDTH, y+PALETTE_EDITOR_HEIGHT)
+}
+
+// rgbToHSV returns hue in degrees and saturation and value as percentages.
+func rgbToHSV(c color.RGBA) (int, int, int) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	hue := 0.0
+	switch {
+	case delta == 0:
+	case max == r:
+		hue = math.Mod((g-b)/delta, 6)
+	case max == g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+	hue = math.Mod(hue*60+360, 360)
+	saturation := 0.0
+	if max > 0 {
+		saturation = delta / max
+	}
+	return int(math.Round(hue)) % 360, int(math.Round(saturation * 100)), int(math.Round(max * 100))
+}
+
+func hsvToRGB(hue int, saturation int, value int) color.RGBA {
+	v := float64(value) / 100
+	chroma := v * float64(saturation) / 100
+	h := float64(hue) / 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) % 6 {
+	case 0:
+		r, g, b = chroma, x, 0
+	case 1:
+		r, g, b = x, chroma, 0
+	case 2:
+		r, g, b = 0, chroma, x
+	case 3:
+		r, g, b = 0, x, chroma
+	case 4:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	m := v - chroma
+	channel := func(c float64) uint8 {
+		return uint8(math.Round((c + m) * 255))
+	}
+	return color.RGBA{R: channel(r), G: channel(g), B: channel(b), A: 255}
+}
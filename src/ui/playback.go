@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+type PlaybackHandlers struct {
+	SaveHandler      widget.ButtonClickedHandlerFunc
+	LoadHandler      widget.ButtonClickedHandlerFunc
+	PlayPauseHandler widget.ButtonClickedHandlerFunc
+	StepHandler      widget.ButtonClickedHandlerFunc
+	SpeedHandler     widget.ButtonClickedHandlerFunc
+	ExitHandler      widget.ButtonClickedHandlerFunc
+}
+
+type PlaybackControls struct {
+	window          *widget.Window
+	playbackButtons []*widget.Button
+	playPauseButton *widget.Button
+	speedButton     *widget.Button
+	statusText      *widget.Text
+}
+
+func newPlaybackControls(handlers *PlaybackHandlers, loader *resource.Loader) *PlaybackControls {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(2),
+		)),
+	)
+	stretch := widget.ButtonOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true}))
+
+	container.AddChild(newTextButton("SAVE", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.SaveHandler)))
+	container.AddChild(newTextButton("LOAD", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.LoadHandler)))
+
+	playPauseButton := newTextButton("PAUSE", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.PlayPauseHandler))
+	stepButton := newTextButton("STEP", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.StepHandler))
+	speedButton := newTextButton("X1", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.SpeedHandler))
+	exitButton := newTextButton("EXIT", loader, stretch, widget.ButtonOpts.ClickedHandler(handlers.ExitHandler))
+	playbackButtons := []*widget.Button{playPauseButton, stepButton, speedButton, exitButton}
+	for _, button := range playbackButtons {
+		container.AddChild(button)
+	}
+
+	statusText := widget.NewText(
+		widget.TextOpts.Text("", loader.LoadFont(assets.FontDefault).Face, color.White),
+	)
+	container.AddChild(statusText)
+
+	window := widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Location(image.Rect(5, 40, 55, 200)),
+	)
+
+	return &PlaybackControls{
+		window:          window,
+		playbackButtons: playbackButtons,
+		playPauseButton: playPauseButton,
+		speedButton:     speedButton,
+		statusText:      statusText,
+	}
+}
+
+func (controls *PlaybackControls) update(state *State) {
+	for _, button := range controls.playbackButtons {
+		button.GetWidget().Disabled = !state.Playback
+	}
+	if state.PlaybackPlaying {
+		controls.playPauseButton.Text().Label = "PAUSE"
+	} else {
+		controls.playPauseButton.Text().Label = "PLAY"
+	}
+	controls.speedButton.Text().Label = fmt.Sprintf("X%d", state.PlaybackSpeed)
+	if state.Playback {
+		controls.statusText.Label = fmt.Sprintf("%d/%d", state.PlaybackProgress, state.PlaybackTotal)
+	} else {
+		controls.statusText.Label = ""
+	}
+}
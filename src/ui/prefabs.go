@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+)
+
+const (
+	PREFAB_PANEL_WIDTH    = 150
+	PREFAB_PANEL_HEIGHT   = 260
+	PREFAB_THUMBNAIL_SIZE = 32
+	PREFAB_PAGE_SIZE      = 6
+)
+
+// PrefabHandlers saves the area selected on the board as a prefab called name.
+type PrefabHandlers struct {
+	Save func(name string) error
+}
+
+// PrefabEntry is a prefab in the library. File picks it out, as names need not be unique.
+type PrefabEntry struct {
+	File      string
+	Name      string
+	Thumbnail *ebiten.Image
+}
+
+// PrefabPanel lists the prefab library while the prefab tool is in use.
+type PrefabPanel struct {
+	window       *widget.Window
+	list         *widget.Container
+	pageText     *widget.Text
+	nameInput    *widget.TextInput
+	rotateButton *widget.Button
+	mirrorButton *widget.Button
+	entries      []PrefabEntry
+	buttons      []*widget.Button
+	files        []string
+	page         int
+	shownPage    int
+	loader       *resource.Loader
+	removeWindow widget.RemoveWindowFunc
+}
+
+func newPrefabPanel(state *State, handlers *PrefabHandlers, loader *resource.Loader) *PrefabPanel {
+	panel := &PrefabPanel{loader: loader, shownPage: -1}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(4),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 6, Bottom: 6, Left: 6, Right: 6}),
+		)),
+	)
+	stretch := widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})
+
+	panel.list = widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, true}, nil),
+			widget.GridLayoutOpts.Spacing(2, 2),
+		)),
+		widget.ContainerOpts.WidgetOpts(stretch, widget.WidgetOpts.MinSize(0, 3*(PREFAB_THUMBNAIL_SIZE+24))),
+	)
+	container.AddChild(panel.list)
+
+	pages := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(4))),
+		widget.ContainerOpts.WidgetOpts(stretch),
+	)
+	pages.AddChild(newTextButton("<", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		panel.page--
+	})))
+	panel.pageText = widget.NewText(
+		widget.TextOpts.Text("0/0", face, color.White),
+		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	pages.AddChild(panel.pageText)
+	pages.AddChild(newTextButton(">", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		panel.page++
+	})))
+	container.AddChild(pages)
+
+	orientation := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(2))),
+	)
+	panel.rotateButton = newTextButton("ROT 0", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		state.PrefabRotation = (state.PrefabRotation + 1) % 4
+	}))
+	orientation.AddChild(panel.rotateButton)
+	var mirrorToggled widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+		state.PrefabMirror = args.State == widget.WidgetChecked
+	}
+	panel.mirrorButton = newTextButton("FLIP", loader,
+		widget.ButtonOpts.ToggleMode(),
+		widget.ButtonOpts.StateChangedHandler(mirrorToggled),
+	)
+	orientation.AddChild(panel.mirrorButton)
+	container.AddChild(orientation)
+
+	save := func(name string) {
+		if err := handlers.Save(name); err != nil {
+			state.Error(err.Error())
+			return
+		}
+		panel.nameInput.InputText = ""
+		panel.nameInput.Focus(false)
+	}
+	panel.nameInput = newTextInput(loader,
+		widget.TextInputOpts.Placeholder("NAME"),
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			save(args.InputText)
+		}),
+		widget.TextInputOpts.WidgetOpts(stretch),
+	)
+	container.AddChild(panel.nameInput)
+	container.AddChild(newTextButton("SAVE SELECTION", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		save(panel.nameInput.InputText)
+	})))
+
+	panel.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Location(prefabPanelRect()),
+	)
+	return panel
+}
+
+func (panel *PrefabPanel) isOpen() bool {
+	return panel.removeWindow != nil
+}
+
+func (panel *PrefabPanel) pageCount() int {
+	return (len(panel.entries) + PREFAB_PAGE_SIZE - 1) / PREFAB_PAGE_SIZE
+}
+
+// rebuildList shows the current page of prefabs, each as a thumbnail over a button
+// that chooses it for stamping.
+func (panel *PrefabPanel) rebuildList(state *State) {
+	panel.list.RemoveChildren()
+	panel.buttons = panel.buttons[:0]
+	panel.files = panel.files[:0]
+	start := panel.page * PREFAB_PAGE_SIZE
+	for i := start; i < len(panel.entries) && i < start+PREFAB_PAGE_SIZE; i++ {
+		file := panel.entries[i].File
+		entry := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+				widget.RowLayoutOpts.Spacing(2),
+			)),
+		)
+		entry.AddChild(widget.NewGraphic(
+			widget.GraphicOpts.Image(panel.entries[i].Thumbnail),
+			widget.GraphicOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter})),
+		))
+		button := newTextButton(panel.entries[i].Name, panel.loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				if state.Prefab == file {
+					state.Prefab = ""
+				} else {
+					state.Prefab = file
+				}
+			}),
+			widget.ButtonOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+		)
+		entry.AddChild(button)
+		panel.list.AddChild(entry)
+		panel.buttons = append(panel.buttons, button)
+		panel.files = append(panel.files, file)
+	}
+	panel.shownPage = panel.page
+}
+
+// SetPrefabs replaces the prefabs listed in the panel.
+func (ui *UI) SetPrefabs(entries []PrefabEntry) {
+	ui.prefabPanel.entries = entries
+	ui.prefabPanel.shownPage = -1
+}
+
+// updatePrefabPanel shows the panel only while the prefab tool is in use.
+func (ui *UI) updatePrefabPanel() {
+	panel := ui.prefabPanel
+	if open := ui.State.Tool == TOOL_PREFAB; open != panel.isOpen() {
+		if open {
+			panel.removeWindow = ui.ebitenUI.AddWindow(panel.window)
+		} else {
+			panel.nameInput.Focus(false)
+			panel.removeWindow()
+			panel.removeWindow = nil
+		}
+	}
+	ui.State.PrefabNaming = panel.isOpen() && panel.nameInput.IsFocused()
+	if !panel.isOpen() {
+		return
+	}
+
+	if panel.page >= panel.pageCount() {
+		panel.page = panel.pageCount() - 1
+	}
+	if panel.page < 0 {
+		panel.page = 0
+	}
+	if panel.shownPage != panel.page {
+		panel.rebuildList(ui.State)
+	}
+	panel.pageText.Label = fmt.Sprintf("%d/%d", panel.page+1, panel.pageCount())
+	if panel.pageCount() == 0 {
+		panel.pageText.Label = "EMPTY"
+	}
+	for i, button := range panel.buttons {
+		syncToggle(button, panel.files[i] == ui.State.Prefab)
+	}
+	panel.rotateButton.Text().Label = fmt.Sprintf("ROT %d", ui.State.PrefabRotation*90)
+	syncToggle(panel.mirrorButton, ui.State.PrefabMirror)
+}
+
+// prefabPanelRect pins the panel to the left edge, below the top toolbar.
+func prefabPanelRect() image.Rectangle {
+	return image.Rect(5, 36, 5+PREFAB_PANEL_WIDTH, 36+PREFAB_PANEL_HEIGHT)
+}
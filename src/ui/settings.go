@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	SETTINGS_WIDTH  = 220
+	SETTINGS_HEIGHT = 190
+)
+
+// SettingsMenu edits State.Settings in place; the game applies and saves them.
+type SettingsMenu struct {
+	window           *widget.Window
+	fullscreenToggle *widget.Button
+	vsyncToggle      *widget.Button
+	pixelToggle      *widget.Button
+	animationToggle  *widget.Button
+	scaleButton      *widget.Button
+	backgroundButton *widget.Button
+	hoverTintSlider  *widget.Slider
+	removeWindow     widget.RemoveWindowFunc
+	ui               *UI
+}
+
+func newSettingsMenu(state *State, loader *resource.Loader) *SettingsMenu {
+	menu := &SettingsMenu{}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+			widget.GridLayoutOpts.Spacing(8, 4),
+			widget.GridLayoutOpts.Padding(widget.Insets{Top: 6, Bottom: 6, Left: 6, Right: 6}),
+		)),
+	)
+	addRow := func(label string, control widget.PreferredSizeLocateableWidget) {
+		container.AddChild(widget.NewText(
+			widget.TextOpts.Text(label, face, color.White),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		))
+		container.AddChild(control)
+	}
+	newToggle := func(toggled func(checked bool)) *widget.Button {
+		return newTextButton("ON", loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.StateChangedHandler(func(args *widget.ButtonChangedEventArgs) {
+				toggled(args.State == widget.WidgetChecked)
+			}),
+		)
+	}
+
+	menu.fullscreenToggle = newToggle(func(checked bool) { state.Settings.Fullscreen = checked })
+	addRow("FULLSCREEN", menu.fullscreenToggle)
+	menu.vsyncToggle = newToggle(func(checked bool) { state.Settings.VSync = checked })
+	addRow("VSYNC", menu.vsyncToggle)
+	menu.scaleButton = newTextButton("", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		state.Settings.Scale = state.Settings.Scale%config.MAX_SCALE + 1
+	}))
+	addRow("SCALE", menu.scaleButton)
+	menu.pixelToggle = newToggle(func(checked bool) { state.Settings.PixelPerfect = checked })
+	addRow("PIXEL PERFECT", menu.pixelToggle)
+	menu.backgroundButton = newTextButton("", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		next := (state.Settings.BackgroundIndex() + 1) % len(config.BACKGROUND_COLOURS)
+		state.Settings.Background = config.BACKGROUND_COLOURS[next].Name
+	}))
+	addRow("BACKGROUND", menu.backgroundButton)
+
+	menu.hoverTintSlider = newSlider(0, 100, func(args *widget.SliderChangedEventArgs) {
+		state.Settings.HoverTint = args.Current
+	}, loader)
+	addRow("HOVER TINT", menu.hoverTintSlider)
+	menu.animationToggle = newToggle(func(checked bool) { state.Settings.Animations = checked })
+	addRow("ANIMATIONS", menu.animationToggle)
+
+	container.AddChild(widget.NewContainer())
+	container.AddChild(newTextButton("CLOSE", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		menu.ui.ToggleSettings()
+	})))
+
+	menu.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Modal(),
+		widget.WindowOpts.Location(settingsRect(config.ScreenWidth, config.ScreenHeight)),
+	)
+	menu.update(state)
+	return menu
+}
+
+func (menu *SettingsMenu) isOpen() bool {
+	return menu.removeWindow != nil
+}
+
+// update reflects settings changed outside the menu, such as by the fullscreen key.
+func (menu *SettingsMenu) update(state *State) {
+	settings := state.Settings
+	syncToggle(menu.fullscreenToggle, settings.Fullscreen)
+	syncToggle(menu.vsyncToggle, settings.VSync)
+	syncToggle(menu.pixelToggle, settings.PixelPerfect)
+	syncToggle(menu.animationToggle, settings.Animations)
+	menu.scaleButton.Text().Label = fmt.Sprintf("X%d", settings.Scale)
+	menu.backgroundButton.Text().Label = config.BACKGROUND_COLOURS[settings.BackgroundIndex()].Name
+	if menu.hoverTintSlider.Current != settings.HoverTint {
+		menu.hoverTintSlider.Current = settings.HoverTint
+	}
+}
+
+func (ui *UI) ToggleSettings() {
+	menu := ui.settingsMenu
+	if menu.isOpen() {
+		menu.removeWindow()
+		menu.removeWindow = nil
+	} else {
+		menu.ui = ui
+		menu.removeWindow = ui.ebitenUI.AddWindow(menu.window)
+	}
+	ui.State.SettingsOpen = menu.isOpen()
+}
+
+func settingsRect(screenWidth int, screenHeight int) image.Rectangle {
+	x := (screenWidth - SETTINGS_WIDTH) / 2
+	y := (screenHeight - SETTINGS_HEIGHT) / 2
+	return image.Rect(x, y, x+SETTINGS_WIDTH, y+SETTINGS_HEIGHT)
+}
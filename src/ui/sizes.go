@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+)
+
+// SizePicker has a button for each size the selected colour can be placed at.
+type SizePicker struct {
+	container  *widget.Container
+	loader     *resource.Loader
+	sizes      []BlockSize
+	buttons    []*widget.Button
+	radioGroup *widget.RadioGroup
+}
+
+func newSizePicker(state *State, loader *resource.Loader) *SizePicker {
+	picker := &SizePicker{
+		container: widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Spacing(2),
+				widget.RowLayoutOpts.Padding(widget.Insets{Top: 8}),
+			)),
+		),
+		loader: loader,
+	}
+	picker.build(state, DEFAULT_SIZES)
+	return picker
+}
+
+func (picker *SizePicker) build(state *State, sizes []BlockSize) {
+	picker.container.RemoveChildren()
+	picker.sizes = sizes
+	picker.buttons = nil
+
+	elements := []widget.RadioGroupElement{}
+	for _, size := range sizes {
+		size := size
+		var sizeChanged widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+			if args.State == widget.WidgetChecked {
+				state.BlockSize = size
+			}
+		}
+		button := newTextButton(size.String(), picker.loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.StateChangedHandler(sizeChanged),
+		)
+		picker.container.AddChild(button)
+		picker.buttons = append(picker.buttons, button)
+		elements = append(elements, button)
+	}
+	picker.radioGroup = widget.NewRadioGroup(
+		widget.RadioGroupOpts.Elements(elements...),
+	)
+}
+
+// update lists the sizes of the selected colour, moving the chosen size to the nearest
+// one it has. The buttons are disabled while the cursor is selected.
+func (picker *SizePicker) update(state *State) {
+	blockOperation := *state.BlockOperation
+	disabled := blockOperation == SELECT
+	if !disabled {
+		if sizes := state.Palette.Sizes(blockOperation); !equalSizes(sizes, picker.sizes) {
+			picker.build(state, sizes)
+		}
+		state.BlockSize = state.Palette.NearestSize(blockOperation, state.BlockSize)
+	}
+	for i, button := range picker.buttons {
+		button.GetWidget().Disabled = disabled
+		if picker.sizes[i] == state.BlockSize && picker.radioGroup.Active() != button {
+			picker.radioGroup.SetActive(button)
+		}
+	}
+}
+
+func equalSizes(a []BlockSize, b []BlockSize) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	ebitenimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/timothy-ch-cheung/go-game-block-placement/assets"
+	"github.com/timothy-ch-cheung/go-game-block-placement/game/config"
+)
+
+const (
+	STATS_WIDTH   = 240
+	STATS_HEIGHT  = 220
+	STATS_REFRESH = 30
+)
+
+// StatsHandlers describes the board as lines of text and exports its report as csv or json.
+type StatsHandlers struct {
+	Summary func() []string
+	Export  func(format string)
+}
+
+// StatsPanel shows the board's report, refreshed while it is open.
+type StatsPanel struct {
+	window       *widget.Window
+	text         *widget.Text
+	handlers     *StatsHandlers
+	ticks        int
+	removeWindow widget.RemoveWindowFunc
+	ui           *UI
+}
+
+func newStatsPanel(handlers *StatsHandlers, loader *resource.Loader) *StatsPanel {
+	panel := &StatsPanel{handlers: handlers}
+	face := loader.LoadFont(assets.FontDefault).Face
+
+	container := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(ebitenimage.NewNineSliceColor(color.RGBA{R: 21, G: 29, B: 40, A: 230})), // #151d28
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(6),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 6, Bottom: 6, Left: 6, Right: 6}),
+		)),
+	)
+	panel.text = widget.NewText(
+		widget.TextOpts.Text("", face, color.White),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+	)
+	container.AddChild(panel.text)
+
+	buttons := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(4))),
+	)
+	for _, format := range []string{"csv", "json"} {
+		format := format
+		buttons.AddChild(newTextButton(strings.ToUpper(format), loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			handlers.Export(format)
+		})))
+	}
+	buttons.AddChild(newTextButton("CLOSE", loader, widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		panel.ui.ToggleStats()
+	})))
+	container.AddChild(buttons)
+
+	panel.window = widget.NewWindow(
+		widget.WindowOpts.Contents(container),
+		widget.WindowOpts.Modal(),
+		widget.WindowOpts.Location(statsRect(config.ScreenWidth, config.ScreenHeight)),
+	)
+	return panel
+}
+
+func (panel *StatsPanel) isOpen() bool {
+	return panel.removeWindow != nil
+}
+
+// update walks the board only every STATS_REFRESH ticks, since the report covers every stack.
+func (panel *StatsPanel) update() {
+	if !panel.isOpen() {
+		return
+	}
+	if panel.ticks%STATS_REFRESH == 0 {
+		panel.text.Label = strings.Join(panel.handlers.Summary(), "\n")
+	}
+	panel.ticks++
+}
+
+func (ui *UI) ToggleStats() {
+	panel := ui.statsPanel
+	if panel.isOpen() {
+		panel.removeWindow()
+		panel.removeWindow = nil
+	} else {
+		panel.ui = ui
+		panel.ticks = 0
+		panel.removeWindow = ui.ebitenUI.AddWindow(panel.window)
+	}
+}
+
+func statsRect(screenWidth int, screenHeight int) image.Rectangle {
+	x := (screenWidth - STATS_WIDTH) / 2
+	y := (screenHeight - STATS_HEIGHT) / 2
+	return image.Rect(x, y, x+STATS_WIDTH, y+STATS_HEIGHT)
+}
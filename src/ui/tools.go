@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"github.com/ebitenui/ebitenui/widget"
+	resource "github.com/quasilyte/ebitengine-resource"
+)
+
+type ToolButtons struct {
+	container  *widget.Container
+	radioGroup *widget.RadioGroup
+	elements   []widget.RadioGroupElement
+}
+
+func newToolButtons(state *State, loader *resource.Loader) *ToolButtons {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(2),
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 8}),
+		)),
+	)
+
+	tools := []struct {
+		label string
+		tool  Tool
+	}{
+		{label: "BUILD", tool: TOOL_BUILD},
+		{label: "FILL", tool: TOOL_FILL},
+		{label: "MEAS", tool: TOOL_MEASURE},
+		{label: "NOTE", tool: TOOL_NOTE},
+		{label: "PREF", tool: TOOL_PREFAB},
+	}
+
+	elements := []widget.RadioGroupElement{}
+	for _, option := range tools {
+		tool := option.tool
+		var toolChanged widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+			if args.State == widget.WidgetChecked {
+				state.Tool = tool
+			}
+		}
+		button := newTextButton(option.label, loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.StateChangedHandler(toolChanged),
+		)
+		container.AddChild(button)
+		elements = append(elements, button)
+	}
+
+	radioGroup := widget.NewRadioGroup(
+		widget.RadioGroupOpts.Elements(elements...),
+	)
+	radioGroup.SetActive(elements[state.Tool])
+
+	return &ToolButtons{
+		container:  container,
+		radioGroup: radioGroup,
+		elements:   elements,
+	}
+}
+
+func (buttons *ToolButtons) update(state *State) {
+	if buttons.radioGroup.Active() != buttons.elements[state.Tool] {
+		buttons.radioGroup.SetActive(buttons.elements[state.Tool])
+	}
+}
@@ -13,30 +13,102 @@ import (
 )
 
 type Handlers struct {
-	ViewToggleChangedHandler *widget.CheckboxChangedHandlerFunc
-	BlockSizeChangedHandler  *widget.CheckboxChangedHandlerFunc
+	Playback *PlaybackHandlers
+	Console  *ConsoleHandlers
+	Notes    *NoteHandlers
+	Prefabs  *PrefabHandlers
+	Stats    *StatsHandlers
 }
 
 type State struct {
-	Renderer       Renderer
-	BlockSize      BlockSize
-	BlockOperation *BlockOperation
-	AnimateAlert   bool
+	Renderer         Renderer
+	ElevationAxis    ElevationAxis
+	ElevationIndex   int
+	LayerHeight      int
+	Overlays         Overlay
+	Tool             Tool
+	Prefab           string
+	PrefabRotation   int
+	PrefabMirror     bool
+	PrefabNaming     bool
+	Settings         config.Settings
+	SettingsOpen     bool
+	Volume           int
+	Muted            bool
+	BlockSize        BlockSize
+	BlockOperation   *BlockOperation
+	Palette          *Palette
+	PaletteOpen      bool
+	SharedSession    bool
+	CursorOverUI     bool
+	ConsoleOpen      bool
+	NoteOpen         bool
+	Playback         bool
+	PlaybackPlaying  bool
+	PlaybackSpeed    int
+	PlaybackProgress int
+	PlaybackTotal    int
+	sounds           []sound
+	notifications    []Notification
+	noteRequest      *noteRequest
 }
 
 type UI struct {
-	ebitenUI  *ebitenui.UI
-	State     *State
-	AlertText *AlertText
+	ebitenUI          *ebitenui.UI
+	State             *State
+	Notifications     *Notifications
+	Minimap           *Minimap
+	elevationControls *ElevationControls
+	layerControls     *LayerControls
+	audioPlayer       *AudioPlayer
+	playbackControls  *PlaybackControls
+	rendererButtons   *RendererButtons
+	console           *Console
+	settingsMenu      *SettingsMenu
+	paletteEditor     *PaletteEditor
+	colourButtons     *BlockColourButtons
+	sizePicker        *SizePicker
+	toolButtons       *ToolButtons
+	noteEditor        *NoteEditor
+	prefabPanel       *PrefabPanel
+	statsPanel        *StatsPanel
+	overlayToggles    *OverlayToggles
+	animationToggle   *widget.Button
+	minimapWindow     *widget.Window
+	windows           []*widget.Window
+	screenWidth       int
+	screenHeight      int
 }
 
 func (ui *UI) Update() {
 	ui.ebitenUI.Update()
-	if ui.State.AnimateAlert {
-		ui.AlertText.Animate()
+	ui.elevationControls.update(ui.State)
+	ui.layerControls.update(ui.State)
+	ui.playbackControls.update(ui.State)
+	ui.rendererButtons.update(ui.State)
+	ui.console.update()
+	ui.settingsMenu.update(ui.State)
+	ui.colourButtons.update(ui.State)
+	ui.sizePicker.update(ui.State)
+	ui.toolButtons.update(ui.State)
+	ui.updateNoteEditor()
+	ui.updatePrefabPanel()
+	ui.statsPanel.update()
+	ui.overlayToggles.update(ui.State)
+	ui.paletteEditor.update(ui.State)
+	syncToggle(ui.animationToggle, ui.State.Settings.Animations)
+	ui.layoutWindows()
+	ui.State.CursorOverUI = ui.console.isOpen() && containsCursor(ui.console.window) ||
+		ui.settingsMenu.isOpen() && containsCursor(ui.settingsMenu.window) ||
+		ui.paletteEditor.isOpen() && containsCursor(ui.paletteEditor.window) ||
+		ui.noteEditor.isOpen() && containsCursor(ui.noteEditor.window) ||
+		ui.prefabPanel.isOpen() && containsCursor(ui.prefabPanel.window) ||
+		ui.statsPanel.isOpen() && containsCursor(ui.statsPanel.window)
+	for _, window := range ui.windows {
+		ui.State.CursorOverUI = ui.State.CursorOverUI || containsCursor(window)
 	}
-	ui.AlertText.update()
-	ui.State.AnimateAlert = false
+	ui.Notifications.update(ui.State)
+	ui.audioPlayer.update(ui.State)
 }
 
 func (ui *UI) Draw(screen *ebiten.Image) {
@@ -106,114 +178,156 @@ func newCheckbox(
 	}
 }
 
-func newViewToggle(handler *widget.CheckboxChangedHandlerFunc, loader *resource.Loader) *widget.Checkbox {
-	return newCheckbox(
-		handler,
-		loader.LoadImage(assets.ImgViewBtn2D).Data,
-		loader.LoadImage(assets.ImgViewBtnIso).Data,
-		loader.LoadImage(assets.ImgViewBtnDisabled).Data,
-		widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
-			VerticalPosition:   widget.AnchorLayoutPositionStart,
-			HorizontalPosition: widget.AnchorLayoutPositionStart,
-		}),
-	)
+func newTextButton(label string, loader *resource.Loader, opts ...widget.ButtonOpt) *widget.Button {
+	image := &widget.ButtonImage{
+		Idle:         newImageNineSlice(loader.LoadImage(assets.ImgTextBtnIdle).Data, 10, 10),
+		Hover:        newImageNineSlice(loader.LoadImage(assets.ImgTextBtnHover).Data, 10, 10),
+		Pressed:      newImageNineSlice(loader.LoadImage(assets.ImgTextBtnSelected).Data, 10, 10),
+		PressedHover: newImageNineSlice(loader.LoadImage(assets.ImgTextBtnSelected).Data, 10, 10),
+		Disabled:     newImageNineSlice(loader.LoadImage(assets.ImgTextBtnDisabled).Data, 10, 10),
+	}
+	textColor := &widget.ButtonTextColor{
+		Idle:     color.White,
+		Disabled: color.RGBA{R: 87, G: 114, B: 119, A: 255}, // #577277
+	}
+
+	return widget.NewButton(append([]widget.ButtonOpt{
+		widget.ButtonOpts.Image(image),
+		widget.ButtonOpts.Text(label, loader.LoadFont(assets.FontDefault).Face, textColor),
+		widget.ButtonOpts.TextPadding(widget.Insets{Top: 3, Bottom: 3, Left: 5, Right: 5}),
+	}, opts...)...)
 }
 
-func newSizeToggle(handler *widget.CheckboxChangedHandlerFunc, loader *resource.Loader) *widget.Checkbox {
-	return newCheckbox(
-		handler,
-		loader.LoadImage(assets.ImgSizeBtnFull).Data,
-		loader.LoadImage(assets.ImgSizeBtnHalf).Data,
-		loader.LoadImage(assets.ImgSizeBtnDisabled).Data,
-		widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
-			VerticalPosition:   widget.AnchorLayoutPositionEnd,
-			HorizontalPosition: widget.AnchorLayoutPositionCenter,
+func newTextInput(loader *resource.Loader, opts ...widget.TextInputOpt) *widget.TextInput {
+	face := loader.LoadFont(assets.FontDefault).Face
+	inputImage := newImageNineSlice(loader.LoadImage(assets.ImgTextBtnIdle).Data, 10, 10)
+	return widget.NewTextInput(append([]widget.TextInputOpt{
+		widget.TextInputOpts.Image(&widget.TextInputImage{Idle: inputImage, Disabled: inputImage}),
+		widget.TextInputOpts.Color(&widget.TextInputColor{
+			Idle:          color.White,
+			Disabled:      color.RGBA{R: 87, G: 114, B: 119, A: 255}, // #577277
+			Caret:         color.White,
+			DisabledCaret: color.RGBA{R: 87, G: 114, B: 119, A: 255},
 		}),
+		widget.TextInputOpts.Face(face),
+		widget.TextInputOpts.Padding(widget.Insets{Top: 4, Bottom: 4, Left: 5, Right: 5}),
+		widget.TextInputOpts.CaretOpts(widget.CaretOpts.Size(face, 2)),
+	}, opts...)...)
+}
+
+func newSlider(min int, max int, handler widget.SliderChangedHandlerFunc, loader *resource.Loader) *widget.Slider {
+	track := newImageNineSlice(loader.LoadImage(assets.ImgTextBtnIdle).Data, 10, 10)
+	handle := newImageNineSlice(loader.LoadImage(assets.ImgTextBtnSelected).Data, 10, 10)
+	return widget.NewSlider(
+		widget.SliderOpts.Direction(widget.DirectionHorizontal),
+		widget.SliderOpts.MinMax(min, max),
+		widget.SliderOpts.Images(
+			&widget.SliderTrackImage{Idle: track, Hover: track},
+			&widget.ButtonImage{Idle: handle, Hover: handle, Pressed: handle},
+		),
+		widget.SliderOpts.FixedHandleSize(8),
+		widget.SliderOpts.ChangedHandler(handler),
+		widget.SliderOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(64, 18),
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter}),
+		),
 	)
 }
 
-func newBlockColourRadioBtns(loader *resource.Loader) (*widget.Container, *BlockOperation) {
+func newAnimationToggle(state *State, loader *resource.Loader) (*widget.Container, *widget.Button) {
 	container := widget.NewContainer(
-		widget.ContainerOpts.Layout(widget.NewRowLayout()),
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Padding(widget.Insets{Top: 8}),
+		)),
 	)
-	var checkboxes []*widget.Checkbox
-	blockOperation := SELECT
 
-	var cursorBlockChanged widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if int(args.State) > 0 {
-			blockOperation = SELECT
-		}
+	var animationToggled widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+		state.Settings.Animations = args.State == widget.WidgetChecked
 	}
-	cursorBlock := newCheckbox(
-		&cursorBlockChanged,
-		loader.LoadImage(assets.ImgCursorBtnSelected).Data,
-		loader.LoadImage(assets.ImgCursorBtnIdle).Data,
-		loader.LoadImage(assets.ImgPanelBtnDisabled).Data,
+	animationToggle := newTextButton("ANIM", loader,
+		widget.ButtonOpts.ToggleMode(),
+		widget.ButtonOpts.StateChangedHandler(animationToggled),
 	)
-	container.AddChild(cursorBlock)
-	checkboxes = append(checkboxes, cursorBlock)
+	syncToggle(animationToggle, state.Settings.Animations)
+	container.AddChild(animationToggle)
 
-	var blueBlockChanged widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if int(args.State) > 0 {
-			blockOperation = PLACE_BLUE
-		}
-	}
-	blueBlock := newCheckbox(
-		&blueBlockChanged,
-		loader.LoadImage(assets.ImgBlueBlockBtnSelected).Data,
-		loader.LoadImage(assets.ImgBlueBlockBtnIdle).Data,
-		loader.LoadImage(assets.ImgPanelBtnDisabled).Data,
-	)
-	container.AddChild(blueBlock)
-	checkboxes = append(checkboxes, blueBlock)
+	return container, animationToggle
+}
 
-	var redBlockChanged widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if int(args.State) > 0 {
-			blockOperation = PLACE_RED
-		}
+// syncToggle checks or unchecks a toggle button to match a value changed elsewhere.
+func syncToggle(button *widget.Button, checked bool) {
+	state := widget.WidgetUnchecked
+	if checked {
+		state = widget.WidgetChecked
+	}
+	if button.State() != state {
+		button.SetState(state)
 	}
-	redBlock := newCheckbox(
-		&redBlockChanged,
-		loader.LoadImage(assets.ImgRedBlockBtnSelected).Data,
-		loader.LoadImage(assets.ImgRedBlockBtnIdle).Data,
-		loader.LoadImage(assets.ImgPanelBtnDisabled).Data,
+}
+
+type RendererButtons struct {
+	container  *widget.Container
+	radioGroup *widget.RadioGroup
+	elements   []widget.RadioGroupElement
+}
+
+func newRendererRadioBtns(state *State, loader *resource.Loader) *RendererButtons {
+	container := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(2))),
 	)
-	container.AddChild(redBlock)
-	checkboxes = append(checkboxes, redBlock)
 
-	var yellowBlockChanged widget.CheckboxChangedHandlerFunc = func(args *widget.CheckboxChangedEventArgs) {
-		if int(args.State) > 0 {
-			blockOperation = PLACE_YELLOW
-		}
+	renderers := []struct {
+		label    string
+		renderer Renderer
+	}{
+		{label: "ISO", renderer: ISOMETRIC},
+		{label: "2D", renderer: TWO_DIMENSIONAL},
+		{label: "SIDE", renderer: ELEVATION},
 	}
-	yellowBlock := newCheckbox(
-		&yellowBlockChanged,
-		loader.LoadImage(assets.ImgYellowBlockBtnSelected).Data,
-		loader.LoadImage(assets.ImgYellowBlockBtnIdle).Data,
-		loader.LoadImage(assets.ImgPanelBtnDisabled).Data,
-	)
-	container.AddChild(yellowBlock)
-	checkboxes = append(checkboxes, yellowBlock)
 
 	elements := []widget.RadioGroupElement{}
-	for _, cb := range checkboxes {
-		elements = append(elements, cb)
+	for _, option := range renderers {
+		renderer := option.renderer
+		var rendererChanged widget.ButtonChangedHandlerFunc = func(args *widget.ButtonChangedEventArgs) {
+			if args.State == widget.WidgetChecked && state.Renderer != renderer {
+				state.Renderer = renderer
+				state.PlaySound(assets.AudioToggleRenderer)
+			}
+		}
+		button := newTextButton(option.label, loader,
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.StateChangedHandler(rendererChanged),
+		)
+		container.AddChild(button)
+		elements = append(elements, button)
 	}
 
 	radioGroup := widget.NewRadioGroup(
 		widget.RadioGroupOpts.Elements(elements...),
 	)
-	radioGroup.SetActive(elements[0])
+	radioGroup.SetActive(elements[state.Renderer])
 
-	return container, &blockOperation
+	return &RendererButtons{
+		container:  container,
+		radioGroup: radioGroup,
+		elements:   elements,
+	}
+}
+
+// update follows renderer changes made outside the buttons, such as from the console.
+func (buttons *RendererButtons) update(state *State) {
+	if buttons.radioGroup.Active() != buttons.elements[state.Renderer] {
+		buttons.radioGroup.SetActive(buttons.elements[state.Renderer])
+	}
 }
 
-func NewUserInterface(handlers *Handlers, loader *resource.Loader) *UI {
+func NewUserInterface(handlers *Handlers, settings config.Settings, palette *Palette, minimapSource MinimapSource, loader *resource.Loader) *UI {
+	// The middle row stretches so the toolbars stay anchored to the top and bottom edges.
 	rootContainer := widget.NewContainer(
 		widget.ContainerOpts.Layout(
-			widget.NewRowLayout(widget.RowLayoutOpts.Direction(widget.DirectionVertical),
-				widget.RowLayoutOpts.Spacing(config.ScreenHeight*0.85),
-				widget.RowLayoutOpts.Padding(widget.Insets{
+			widget.NewGridLayout(widget.GridLayoutOpts.Columns(1),
+				widget.GridLayoutOpts.Stretch([]bool{true}, []bool{false, true, false}),
+				widget.GridLayoutOpts.Padding(widget.Insets{
 					Top:    5,
 					Bottom: 5,
 					Left:   5,
@@ -223,32 +337,60 @@ func NewUserInterface(handlers *Handlers, loader *resource.Loader) *UI {
 		),
 	)
 
-	renderer := ISOMETRIC
+	state := &State{
+		Renderer:    ISOMETRIC,
+		BlockSize:   HALF,
+		LayerHeight: minimapSource.MaxHeight(),
+		Settings:    settings,
+		Palette:     palette,
+		Volume:      DEFAULT_VOLUME,
+	}
+
 	topPanelLayout := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
-		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
 	)
 	topPanelContainer := widget.NewContainer(
-		widget.ContainerOpts.Layout(widget.NewRowLayout(
-			widget.RowLayoutOpts.Spacing(250),
-		)),
+		widget.ContainerOpts.Layout(widget.NewRowLayout()),
 		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
 			StretchVertical: true,
 		})),
 	)
-	viewToggle := newViewToggle(handlers.ViewToggleChangedHandler, loader)
-	viewToggle.SetState(widget.WidgetState(renderer))
-	topPanelContainer.AddChild(viewToggle)
-
-	alertText := newAlertText("MAX HEIGHT REACHED!", color.White, loader)
-	topPanelContainer.AddChild(alertText.widget)
+	viewContainer := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(8))),
+	)
+	rendererButtons := newRendererRadioBtns(state, loader)
+	viewContainer.AddChild(rendererButtons.container)
+	elevationControls := newElevationControls(state, loader)
+	viewContainer.AddChild(elevationControls.container)
+	overlayToggles := newOverlayToggles(state, loader)
+	viewContainer.AddChild(overlayToggles.container)
+	topPanelContainer.AddChild(viewContainer)
+	// The UI is built below, after the widgets that the button would toggle.
+	var userInterface *UI
+	menuButtons := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(widget.RowLayoutOpts.Spacing(4))),
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+			HorizontalPosition: widget.AnchorLayoutPositionEnd,
+		})),
+	)
+	menuButtons.AddChild(newTextButton("STATS", loader,
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			userInterface.ToggleStats()
+		}),
+	))
+	menuButtons.AddChild(newTextButton("SET", loader,
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			userInterface.ToggleSettings()
+		}),
+	))
+	topPanelLayout.AddChild(menuButtons)
 
 	topPanelLayout.AddChild(topPanelContainer)
 	rootContainer.AddChild(topPanelLayout)
+	rootContainer.AddChild(widget.NewContainer())
 
 	bottomPanelLayout := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
-		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
 	)
 	bottomPanelContainer := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewRowLayout(
@@ -260,13 +402,18 @@ func NewUserInterface(handlers *Handlers, loader *resource.Loader) *UI {
 	)
 	bottomPanelLayout.AddChild(bottomPanelContainer)
 
-	blockSize := HALF
-	blockSizeToggle := newSizeToggle(handlers.BlockSizeChangedHandler, loader)
-	blockSizeToggle.SetState(widget.WidgetState(blockSize))
-	bottomPanelContainer.AddChild(blockSizeToggle)
+	colourButtons := newBlockColourButtons(state, func() { userInterface.TogglePalette() }, loader)
+	toolButtons := newToolButtons(state, loader)
+	bottomPanelContainer.AddChild(toolButtons.container)
+	sizePicker := newSizePicker(state, loader)
+	bottomPanelContainer.AddChild(sizePicker.container)
+	bottomPanelContainer.AddChild(colourButtons.container)
 
-	blockOperationContainer, blockOperation := newBlockColourRadioBtns(loader)
-	bottomPanelContainer.AddChild(blockOperationContainer)
+	layerControls := newLayerControls(state, loader)
+	bottomPanelContainer.AddChild(layerControls.container)
+	animationToggleContainer, animationToggle := newAnimationToggle(state, loader)
+	bottomPanelContainer.AddChild(animationToggleContainer)
+	bottomPanelContainer.AddChild(newVolumeControls(state, loader))
 
 	rootContainer.AddChild(bottomPanelLayout)
 
@@ -274,15 +421,49 @@ func NewUserInterface(handlers *Handlers, loader *resource.Loader) *UI {
 		Container: rootContainer,
 	}
 
-	state := &State{
-		Renderer:       renderer,
-		BlockSize:      blockSize,
-		BlockOperation: blockOperation,
+	minimap := newMinimap(minimapSource, state)
+	playbackControls := newPlaybackControls(handlers.Playback, loader)
+	minimapWindow := newMinimapWindow(minimap)
+	windows := []*widget.Window{
+		minimapWindow,
+		playbackControls.window,
 	}
-
-	return &UI{
-		ebitenUI:  ui,
-		State:     state,
-		AlertText: alertText,
+	for _, window := range windows {
+		ui.AddWindow(window)
 	}
+	notifications := newNotifications(loader)
+	ui.AddWindow(notifications.window)
+
+	userInterface = &UI{
+		ebitenUI:          ui,
+		State:             state,
+		Notifications:     notifications,
+		Minimap:           minimap,
+		elevationControls: elevationControls,
+		layerControls:     layerControls,
+		audioPlayer:       newAudioPlayer(loader),
+		playbackControls:  playbackControls,
+		rendererButtons:   rendererButtons,
+		console:           newConsole(handlers.Console, loader),
+		settingsMenu:      newSettingsMenu(state, loader),
+		paletteEditor:     newPaletteEditor(state, loader),
+		colourButtons:     colourButtons,
+		sizePicker:        sizePicker,
+		toolButtons:       toolButtons,
+		noteEditor:        newNoteEditor(handlers.Notes, loader),
+		prefabPanel:       newPrefabPanel(state, handlers.Prefabs, loader),
+		statsPanel:        newStatsPanel(handlers.Stats, loader),
+		overlayToggles:    overlayToggles,
+		animationToggle:   animationToggle,
+		minimapWindow:     minimapWindow,
+		windows:           windows,
+		screenWidth:       config.ScreenWidth,
+		screenHeight:      config.ScreenHeight,
+	}
+	return userInterface
+}
+
+// KeyboardCaptured reports whether keys are going to a text input rather than the board.
+func (state *State) KeyboardCaptured() bool {
+	return state.ConsoleOpen || state.PaletteOpen || state.NoteOpen || state.PrefabNaming
 }